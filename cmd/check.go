@@ -0,0 +1,125 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iamlucasvieira/ComTemplate/pkg/cli"
+)
+
+var (
+	checkFile  string
+	checkRange string
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Checks commit messages against the templates",
+	Long: `Checks that commit messages follow one of the templates and exits with a
+    non-zero status when one does not.
+
+    Use '--file' to check a message file, such as .git/COMMIT_EDITMSG, or '-'
+    to read the message from standard input. Use '--range' to check existing
+    commits, for example 'ct check --range main..HEAD'. Messages written by
+    git for merges, reverts and fixups are skipped. As git does, comment lines,
+    starting with core.commentChar, and everything below the scissors line
+    are ignored in message files, but kept in existing commits.
+
+    Select variables must be one of their options and variables with a pattern
+    must match it. For each message that does not match, the closest template
+    and the part that differs are reported.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		templates := readTemplates()
+
+		var commits []cli.CommitMessage
+		if checkFile != "" {
+			// Unlike commits, message files still hold the comments git strips
+			message := cli.StripComments(readMessage(checkFile), cli.CommentChar())
+			commits = []cli.CommitMessage{{Message: message}}
+		} else {
+			var err error
+			commits, err = cli.CommitMessages(checkRange)
+			if err != nil {
+				cli.Write(
+					cli.Header("Error reading commits"),
+					err.Error(),
+				)
+				os.Exit(1)
+			}
+		}
+
+		failed := 0
+		for _, commit := range commits {
+			if cli.GeneratedMessage(commit.Message) {
+				continue
+			}
+
+			match, err := cli.MatchMessage(templates, commit.Message)
+			if err != nil {
+				cli.Write(
+					cli.Header("Error checking message"),
+					err.Error(),
+				)
+				os.Exit(1)
+			}
+
+			name := checkFile
+			if commit.Hash != "" {
+				name = commit.Hash[:7]
+			}
+
+			if match.OK {
+				cli.WriteNoMargin(cli.TextHighlight("✔ ") + name + " " + match.String())
+				continue
+			}
+
+			failed++
+			cli.WriteNoMargin(cli.TextError("✘ ") + name + " " + match.String())
+		}
+
+		if failed > 0 {
+			cli.Write(cli.TextError(fmt.Sprintf("%d of %d message(s) do not match any template", failed, len(commits))))
+			os.Exit(1)
+		}
+	},
+}
+
+// readMessage reads a commit message from path, or from standard input when
+// path is '-'
+func readMessage(path string) string {
+	var data []byte
+	var err error
+
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		cli.Write(
+			cli.Header("Error reading message"),
+			err.Error(),
+		)
+		os.Exit(1)
+	}
+
+	return string(data)
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	checkCmd.Flags().StringVar(&checkFile, "file", "", "Commit message file to check, or '-' for standard input")
+	checkCmd.Flags().StringVar(&checkRange, "range", "", "Commits to check, such as main..HEAD")
+	checkCmd.MarkFlagsMutuallyExclusive("file", "range")
+	checkCmd.MarkFlagsOneRequired("file", "range")
+}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iamlucasvieira/ComTemplate/pkg/cli"
+)
+
+var (
+	hookTemplate string
+	hookType     string
+)
+
+// hookCmd represents the hook command
+var hookCmd = &cobra.Command{
+	Use:   "hook",
+	Short: "Manages the git hooks that run and enforce templates",
+	Long: `Installs or removes git hooks in the current repository.
+
+    Once the prepare-commit-msg hook is installed, 'git commit' opens the
+    template form and uses the result as the commit message.
+
+    Once the commit-msg hook is installed, commits whose message does not
+    match any template are rejected, including messages given with
+    'git commit -m'.`,
+}
+
+// hookInstallCmd represents the hook install command
+var hookInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Installs a git hook",
+	Long: `Installs a git hook in the current repository. The --type flag selects
+    the hook:
+
+    prepare-commit-msg (default): runs a template when committing. Merges,
+    squashes, amends and commits with a message given on the command line
+    are left untouched.
+
+    commit-msg: rejects commits whose message matches no template, naming
+    the closest template and the part that differs. Messages written by
+    git for merges, reverts and fixups are accepted.
+
+    Without --template, the prepare-commit-msg hook uses the only template
+    available or asks which one to use.
+
+    The --config flag, when given, is used by the hook as well. An existing
+    hook is kept and still runs before ct.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		bin, err := os.Executable()
+		if err != nil {
+			cli.Write(
+				cli.Header("Error finding ct executable"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+
+		config := cfgFile
+		if config != "" {
+			config, err = filepath.Abs(config)
+			if err != nil {
+				cli.Write(
+					cli.Header("Error finding config"),
+					err.Error(),
+				)
+				os.Exit(1)
+			}
+		}
+
+		path, err := cli.InstallHook(hookType, bin, hookTemplate, config)
+		if err != nil {
+			cli.Write(
+				cli.Header("Error installing hook"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+
+		cli.Write(
+			fmt.Sprintf("Hook installed at %s", path),
+		)
+	},
+}
+
+// hookUninstallCmd represents the hook uninstall command
+var hookUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Removes a git hook",
+	Long: `Removes the hook of the type given by --type installed by ct and
+    restores the hook that was there before, if any.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		path, err := cli.UninstallHook(hookType)
+		if err != nil {
+			cli.Write(
+				cli.Header("Error removing hook"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+
+		cli.Write(
+			fmt.Sprintf("Hook removed from %s", path),
+		)
+	},
+}
+
+// hookRunCmd represents the hook run command called by the installed hook
+var hookRunCmd = &cobra.Command{
+	Use:    "run <msgfile> [source] [sha]",
+	Short:  "Runs a template from the prepare-commit-msg hook",
+	Hidden: true,
+	Args:   cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		msgFile := args[0]
+		source := ""
+		if len(args) > 1 {
+			source = args[1]
+		}
+
+		if cli.SkipHookSource(source) {
+			return
+		}
+
+		t := getHookTemplate()
+		headerStr := fmt.Sprintf("Using template '%s'", t.Name)
+		cli.Write(
+			cli.Header(headerStr),
+		)
+
+		t, values := applySources(t, nil)
+		text, err := cli.PopulateFromForm(t, values)
+		if err != nil {
+			cli.Write(
+				cli.Header("Error filling template"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+
+		err = cli.PrepareCommitMessage(msgFile, text, source)
+		if err != nil {
+			cli.Write(
+				cli.Header("Error writing commit message"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+	},
+}
+
+// getHookTemplate returns the template selected with --template, the only
+// template available or the one picked from the list
+func getHookTemplate() cli.Template {
+	if hookTemplate != "" {
+		return getTemplate(hookTemplate)
+	}
+
+	data := readTemplates()
+	if len(data) == 1 {
+		return data[0]
+	}
+
+	return pickTemplate()
+}
+
+func init() {
+	rootCmd.AddCommand(hookCmd)
+	hookCmd.AddCommand(hookInstallCmd)
+	hookCmd.AddCommand(hookUninstallCmd)
+	hookCmd.AddCommand(hookRunCmd)
+
+	for _, c := range []*cobra.Command{hookInstallCmd, hookUninstallCmd} {
+		c.Flags().StringVar(&hookType, "type", cli.HookTypes[0], "Hook type: "+strings.Join(cli.HookTypes, " or "))
+	}
+	hookInstallCmd.Flags().StringVar(&hookTemplate, "template", "", "Template used by the hook (default is the only template available, or one picked from a list)")
+	hookRunCmd.Flags().StringVar(&hookTemplate, "template", "", "Template to run")
+}
@@ -4,30 +4,63 @@ Copyright © 2023 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/iamlucasvieira/ComTemplate/pkg/cli"
 )
 
+var (
+	preset     string
+	force      bool
+	initOutput string
+)
+
 // initCmd represents the init command
 var initCmd = &cobra.Command{
 	Use:   "init",
-	Short: "Initializes a default template file",
-	Long: `Creates a default template file named 'comtemplate.yml'
-    at the current directory.`,
+	Short: "Initializes a template file from a preset",
+	Long: `Creates a template file named 'comtemplate.yml' at the current
+    directory from one of the built-in presets:
+
+    ` + strings.Join(cli.Presets(), ", ") + `
+
+    Without --preset, the preset is picked from a list, or the default
+    preset is used when there is no terminal.
+
+    Use '--output' to write the file somewhere else, either a file or a
+    directory, and '--force' to overwrite an existing file.`,
+	Args: cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
-		err := cli.CreateDefault()
+		name := preset
+		if name == "" {
+			name = cli.DefaultPreset
+			if cli.IsTerminal(os.Stdin) {
+				var err error
+				name, err = cli.SelectPreset()
+				if err != nil {
+					cli.Write(
+						cli.Header("Error selecting preset"),
+						err.Error(),
+					)
+					os.Exit(1)
+				}
+			}
+		}
+
+		path, err := cli.CreatePreset(name, initOutput, force)
 		if err != nil {
 			cli.Write(
-				cli.Header("Error creating default file"),
+				cli.Header("Error creating template file"),
 				err.Error(),
 			)
 			os.Exit(1)
 		}
 		cli.Write(
-			"Default file created",
+			fmt.Sprintf("Template file created at %s from preset '%s'", path, name),
 		)
 	},
 }
@@ -35,13 +68,7 @@ var initCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(initCmd)
 
-	// Here you will define your flags and configuration settings.
-
-	// Cobra supports Persistent Flags which will work for this command
-	// and all subcommands, e.g.:
-	// initCmd.PersistentFlags().String("foo", "", "A help for foo")
-
-	// Cobra supports local flags which will only run when this command
-	// is called directly, e.g.:
-	// initCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	initCmd.Flags().StringVarP(&preset, "preset", "p", "", "Preset to write: "+strings.Join(cli.Presets(), ", "))
+	initCmd.Flags().BoolVarP(&force, "force", "f", false, "Overwrite an existing file")
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "comtemplate.yml", "File or directory to write")
 }
@@ -5,6 +5,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
@@ -18,14 +20,16 @@ var listCmd = &cobra.Command{
 	Long: `Returns a list with the name of each template found in
     the template configuration file.
 
-    File is either 'comtemplate.yml' or 'comtemplate.yaml' at the current
-    directory.
+    Files named 'comtemplate.yml' or 'comtemplate.yaml' are looked up in the
+    current directory and its parents up to the git root, then in
+    $XDG_CONFIG_HOME/comtemplate and /etc/comtemplate. Each template is
+    listed with the file it came from.
     `,
 	Run: func(cmd *cobra.Command, args []string) {
-		data := getTemplates()
+		data := readTemplates()
 		titles := []string{}
 		for _, template := range data {
-			titleAndDescription := fmt.Sprintf("%s: %s", template.Name, template.Description)
+			titleAndDescription := fmt.Sprintf("%s: %s %s", template.Name, template.Description, cli.TextSubtle("("+displayPath(template.Source)+")"))
 			titles = append(titles, titleAndDescription)
 		}
 
@@ -34,6 +38,22 @@ var listCmd = &cobra.Command{
 	},
 }
 
+// displayPath returns path relative to the working directory when it is
+// shorter than the absolute path
+func displayPath(path string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+
+	rel, err := filepath.Rel(cwd, path)
+	if err != nil || len(rel) >= len(path) {
+		return path
+	}
+
+	return rel
+}
+
 func init() {
 	rootCmd.AddCommand(listCmd)
 
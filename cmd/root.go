@@ -6,13 +6,27 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
-	"github.com/atotto/clipboard"
 	"github.com/spf13/cobra"
 
 	"github.com/iamlucasvieira/ComTemplate/pkg/cli"
 )
 
+var (
+	cfgFile       string
+	strict        bool
+	commit        bool
+	commitOptions cli.CommitOptions
+	setValues     []string
+	valuesFile    string
+	noInput       bool
+	preview       bool
+	outputFlag    string
+	clipboardFlag string
+	out           cli.Output
+)
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "ct",
@@ -25,44 +39,150 @@ containing default templates of git commit messages. You can edit this file to
 add your own templates.
 
 2. List available templates: 'ct list'
-- This will list all available templates in the 'comtemplate.yml' file.
+- This will list all available templates. Template files are looked up in the
+current directory and its parents up to the git root, then in
+$XDG_CONFIG_HOME/comtemplate and /etc/comtemplate. When names collide, the
+closest template wins. Use '--config <path>' or the COMTEMPLATE_CONFIG
+environment variable to read a specific file or directory instead.
 
 3. Use a template: 'ct <template-name>'
-- This will open a form to fill the template variables. After filling the form,
-the commit message will be printed to the terminal and copied to the clipboard.
+- This will open a form to fill the template variables. Run 'ct' alone to pick
+the template from a list, typing '/' to filter it fuzzily by name and
+description, so that 'cnvtl' finds 'conventional'. After filling the form, the
+commit message will be printed to the terminal and copied to the clipboard.
 You can paste it in your commit message.
+
+4. Commit directly: 'ct <template-name> --commit'
+- This will create the commit in the current repository instead of copying the
+message. The flags --amend, --signoff, --no-verify and --all are passed to git.
+
+5. Fill variables without the form: 'ct <template-name> --set name=value'
+- Values can also be read from a YAML or JSON file with '--values <file>'.
+Variables left without a value are asked for in the form, or use their default
+with '--no-input', failing when they have none. Optional variables only used
+inside their own '%{?name}...%{/name}' block are left empty instead, dropping
+the block.
+
+6. Choose where the message goes: 'ct <template-name> --output stdout'
+- The message can be sent to the clipboard, stdout, a file with 'file:<path>'
+or the repository's COMMIT_EDITMSG with 'git-msg'. When stdout is not a
+terminal, the message is printed to it, so 'ct <template-name> | git commit -F -'
+works. The clipboard backend is picked from the environment: the system
+clipboard locally when a display is available, the terminal clipboard (OSC52)
+over SSH or without display, then a tmux buffer or a file. Use '--clipboard' to force one.
+
+7. Preview the message: 'ct <template-name> --preview'
+- This will show the message next to the form as it is filled, with warnings
+about the length of the subject. The message can then be confirmed, edited or
+cancelled before it is sent anywhere.
 `,
-	Args: cobra.MinimumNArgs(1),
+	Args: cobra.MaximumNArgs(1),
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		for _, name := range []string{"amend", "signoff", "no-verify", "all"} {
+			if cmd.Flags().Changed(name) && !commit {
+				return fmt.Errorf("--%s can only be used with --commit", name)
+			}
+		}
+
+		if outputFlag == "" {
+			outputFlag = cli.OutputClipboard
+			if !commit && !cli.IsTerminal(os.Stdout) {
+				outputFlag = cli.OutputStdout
+			}
+		}
+
+		var err error
+		out, err = cli.ParseOutput(outputFlag)
+		if err != nil {
+			return err
+		}
+
+		out.Clipboard, err = cli.NewClipboard(clipboardFlag)
+		if err != nil {
+			return err
+		}
+
+		// Keep stdout for the commit message only
+		if out.Kind == cli.OutputStdout {
+			cli.SetOutput(os.Stderr)
+		}
+
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		data := getTemplates()
-		t, ok := data[args[0]]
-		if !ok {
-			fmt.Printf("Template '%s' not found\n", args[0])
-			os.Exit(1)
+		var t cli.Template
+		if len(args) > 0 {
+			t = getTemplate(args[0])
+		} else {
+			t = pickTemplate()
 		}
 		headerStr := fmt.Sprintf("Using template '%s'", t.Name)
 		cli.Write(
 			cli.Header(headerStr),
 		)
-		text, err := cli.PopulateFromForm(t)
+		t, values := applySources(t, getValues())
+
+		if noInput {
+			values = cli.FillOptional(t, cli.FillDefaults(t, values))
+			if missing := cli.MissingVariables(t, values); len(missing) > 0 {
+				names := make([]string, len(missing))
+				for i, variable := range missing {
+					names[i] = variable.Name
+				}
+				cli.Write(
+					cli.Header("Missing values"),
+					fmt.Sprintf("No value given for: %s", strings.Join(names, ", ")),
+				)
+				os.Exit(1)
+			}
+		}
+
+		populate := cli.PopulateFromForm
+		if preview {
+			populate = cli.PopulateWithPreview
+		}
+
+		text, err := populate(t, values)
 		if err != nil {
-			fmt.Println(err)
+			cli.Write(
+				cli.Header("Error filling template"),
+				err.Error(),
+			)
 			os.Exit(1)
 		}
 
-		err = clipboard.WriteAll(text)
+		if commit {
+			output, err := cli.Commit(text, commitOptions)
+			if err != nil {
+				cli.Write(
+					cli.Header("Error creating commit"),
+					err.Error(),
+				)
+				os.Exit(1)
+			}
+
+			cli.WriteNoMargin(
+				output,
+				cli.TextHighlight("✔ Commit created"),
+			)
+			return
+		}
+
+		message, err := out.Send(text)
 		if err != nil {
 			cli.Write(
-				cli.Header("Error copying to clipboard"),
+				cli.Header("Error sending commit message"),
 				err.Error(),
 			)
 			os.Exit(1)
 		}
 
-		cli.WriteNoMargin(
-			text,
-			cli.TextHighlight("✔ Copied to clipboard"),
-		)
+		if out.Kind != cli.OutputStdout {
+			cli.WriteNoMargin(
+				text,
+				cli.TextHighlight("✔ "+message),
+			)
+		}
 	},
 	// Uncomment the following line if your bare application
 	// has an action associated with it:
@@ -78,17 +198,74 @@ func Execute() {
 	}
 }
 
-func getTemplates() map[string]cli.Template {
+// configPath returns the template file or directory given with --config or
+// COMTEMPLATE_CONFIG, or an empty string to use discovery
+func configPath() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	return os.Getenv(cli.ConfigEnv)
+}
+
+// readTemplates returns the discovered templates or exits when none can be
+// read
+func readTemplates() []cli.Template {
+	if path := configPath(); path != "" {
+		data, err := cli.Read(path)
+		if validationErrs, ok := err.(cli.ValidationErrors); ok {
+			reportValidationErrors(validationErrs)
+		} else if err != nil {
+			cli.Write(
+				cli.Header("Error reading config"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+		return data
+	}
+
 	data, err := cli.ReadDefault()
-	if err != nil {
-		fmt.Println(`Error reading default file
+	if validationErrs, ok := err.(cli.ValidationErrors); ok {
+		reportValidationErrors(validationErrs)
+	} else if err != nil {
+		cli.Write(
+			cli.Header("Error reading default file"),
+			err.Error(),
+			"",
+			`Make sure you have a file named 'comtemplate.yml' or 'comtemplate.yaml' in the
+current directory, one of its parents up to the git root, $XDG_CONFIG_HOME/comtemplate
+or /etc/comtemplate.`,
+			"",
+			"Run: 'ct init' to create a default file.",
+		)
+		os.Exit(1)
+	}
 
-Make sure you have a file named 'comtemplate.yml' or 'comtemplate.yaml' at the current directory.
+	return data
+}
+
+// reportValidationErrors shows the problems found in the templates and exits
+// in strict mode
+func reportValidationErrors(errs cli.ValidationErrors) {
+	title := "Skipping invalid templates"
+	if strict {
+		title = "Invalid templates"
+	}
 
-Run: 'comtemplate init' to create a default file.
-        `)
+	items := []string{cli.Header(title)}
+	for _, err := range errs {
+		items = append(items, cli.ListItemTick(cli.TextError(err.Error())))
+	}
+	cli.Write(items...)
+
+	if strict {
 		os.Exit(1)
 	}
+}
+
+// getTemplates returns the discovered templates by name
+func getTemplates() map[string]cli.Template {
+	data := readTemplates()
 
 	// Turn into map
 	t := make(map[string]cli.Template)
@@ -98,14 +275,130 @@ Run: 'comtemplate init' to create a default file.
 	return t
 }
 
+// pickTemplate asks which template to use, or exits when there is no
+// terminal to ask on
+func pickTemplate() cli.Template {
+	if noInput || !cli.IsTerminal(os.Stdin) {
+		cli.Write(
+			cli.Header("No template given"),
+			"Run: 'ct <template-name>' or 'ct list' to see the available templates.",
+		)
+		os.Exit(1)
+	}
+
+	t, err := cli.SelectTemplate(readTemplates())
+	if err != nil {
+		cli.Write(
+			cli.Header("Error selecting template"),
+			err.Error(),
+		)
+		os.Exit(1)
+	}
+	return t
+}
+
+// getValues returns the values given with --values and --set, or exits when
+// they cannot be read
+func getValues() map[string]string {
+	values := make(map[string]string)
+
+	if valuesFile != "" {
+		fileValues, err := cli.ReadValues(valuesFile)
+		if err != nil {
+			cli.Write(
+				cli.Header("Error reading values"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+		for name, value := range fileValues {
+			values[name] = value
+		}
+	}
+
+	pairs, err := cli.ParseSet(setValues)
+	if err != nil {
+		cli.Write(
+			cli.Header("Error reading values"),
+			err.Error(),
+		)
+		os.Exit(1)
+	}
+	for name, value := range pairs {
+		values[name] = value
+	}
+
+	return values
+}
+
+// applySources fills values and options of t from their sources, or exits
+// when they cannot be loaded
+func applySources(t cli.Template, values map[string]string) (cli.Template, map[string]string) {
+	settings, err := cli.ReadSettings()
+	if err != nil {
+		cli.Write(
+			cli.Header("Error reading settings"),
+			err.Error(),
+		)
+		os.Exit(1)
+	}
+
+	if skipped := cli.SkippedCommands(t, settings); len(skipped) > 0 {
+		cli.Write(
+			cli.Header("Commands disabled"),
+			cli.SkippedCommandsWarning(skipped),
+		)
+	}
+
+	t, values, err = cli.ApplySources(t, values, settings)
+	if err != nil {
+		cli.Write(
+			cli.Header("Error loading options"),
+			err.Error(),
+		)
+		os.Exit(1)
+	}
+
+	return t, values
+}
+
+// getTemplate returns the template with the given name or exits when it is
+// not found
+func getTemplate(name string) cli.Template {
+	data := getTemplates()
+	t, ok := data[name]
+	if !ok {
+		cli.Write(
+			cli.Header("Template not found"),
+			fmt.Sprintf("Template '%s' not found", name),
+			"Run: 'ct list' to see the available templates.",
+		)
+		os.Exit(1)
+	}
+	return t
+}
+
 func init() {
 	// Here you will define your flags and configuration settings.
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 
-	// rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ComTemplate.yaml)")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "Refuse to run when any template is invalid")
+	rootCmd.PersistentFlags().StringVarP(&cfgFile, "config", "c", "", "Template file or directory to read instead of discovering one (env: "+cli.ConfigEnv+")")
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
-	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
+	rootCmd.Flags().BoolVar(&commit, "commit", false, "Create the commit instead of copying the message to the clipboard")
+	rootCmd.Flags().BoolVar(&commitOptions.Amend, "amend", false, "Amend the previous commit (requires --commit)")
+	rootCmd.Flags().BoolVar(&commitOptions.Signoff, "signoff", false, "Add a Signed-off-by trailer (requires --commit)")
+	rootCmd.Flags().BoolVar(&commitOptions.NoVerify, "no-verify", false, "Bypass the pre-commit and commit-msg hooks (requires --commit)")
+	rootCmd.Flags().BoolVar(&commitOptions.All, "all", false, "Stage all modified and deleted files (requires --commit)")
+	rootCmd.Flags().StringArrayVar(&setValues, "set", nil, "Set a variable value as name=value (can be repeated)")
+	rootCmd.Flags().StringVar(&valuesFile, "values", "", "YAML or JSON file with variable values")
+	rootCmd.Flags().BoolVar(&noInput, "no-input", false, "Fail instead of asking for variables without a value")
+	rootCmd.Flags().StringVarP(&outputFlag, "output", "o", "", "Where to send the message: clipboard, stdout, file:<path> or git-msg (default is stdout when it is not a terminal, clipboard otherwise)")
+	rootCmd.Flags().StringVar(&clipboardFlag, "clipboard", cli.ClipboardAuto, "Clipboard backend: auto, native, osc52, tmux or file")
+	rootCmd.Flags().BoolVar(&preview, "preview", false, "Show a live preview of the message and confirm it before sending it")
+	rootCmd.MarkFlagsMutuallyExclusive("commit", "output")
+	rootCmd.MarkFlagsMutuallyExclusive("preview", "no-input")
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runMainEnv makes the test binary run ct instead of the tests
+const runMainEnv = "CT_TEST_RUN_MAIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(runMainEnv) == "1" {
+		rootCmd.SetArgs(os.Args[1:])
+		Execute()
+		os.Exit(0)
+	}
+
+	os.Exit(m.Run())
+}
+
+// runCt runs ct with args in dir, with stdout and stderr as pipes, and
+// returns both outputs and the exit code
+func runCt(t *testing.T, dir string, args ...string) (string, string, int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0], args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), runMainEnv+"=1", "XDG_CONFIG_HOME="+filepath.Join(dir, ".config"))
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	code := 0
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		code = exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("error running ct: %v", err)
+	}
+
+	return stdout.String(), stderr.String(), code
+}
+
+func TestStdoutOnlyHoldsTheMessage(t *testing.T) {
+	dir := t.TempDir()
+
+	config := filepath.Join(dir, "comtemplate.yml")
+	data := "- name: test\n  text: \"%{?type}%{type}: %{/type}%{title}\"\n  variables:\n    - name: type\n    - name: title\n      required: true\n" +
+		"- name: plain\n  text: \"%{type}: %{title}\"\n  variables:\n    - name: type\n    - name: title\n"
+	if err := os.WriteFile(config, []byte(data), 0644); err != nil {
+		t.Fatalf("error writing template file: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		args        []string
+	}{
+		{description: "unknown template", args: []string{"nosuch"}},
+		{description: "missing values", args: []string{"test", "--no-input"}},
+		{description: "missing optional values outside a block", args: []string{"plain", "--no-input", "--set", "title=x"}},
+		{description: "invalid values", args: []string{"test", "--no-input", "--set", "title="}},
+		{description: "unreadable values file", args: []string{"test", "--values", "missing.yml"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			args := append([]string{"--config", config}, tc.args...)
+			stdout, stderr, code := runCt(t, dir, args...)
+
+			if code == 0 {
+				t.Errorf("expected ct to fail")
+			}
+			if stdout != "" {
+				t.Errorf("expected empty stdout, got %q", stdout)
+			}
+			if stderr == "" {
+				t.Errorf("expected error on stderr")
+			}
+		})
+	}
+
+	t.Run("optional values in their own block left empty", func(t *testing.T) {
+		stdout, stderr, code := runCt(t, dir, "--config", config, "test", "--no-input", "--set", "title=Add tests")
+
+		if code != 0 {
+			t.Fatalf("expected ct to succeed, got exit code %d: %s", code, stderr)
+		}
+		if want := "Add tests"; stdout != want {
+			t.Errorf("expected %q on stdout, got %q", want, stdout)
+		}
+	})
+
+	t.Run("message", func(t *testing.T) {
+		stdout, _, code := runCt(t, dir, "--config", config, "test", "--no-input", "--set", "type=feat", "--set", "title=Add tests")
+
+		if code != 0 {
+			t.Fatalf("expected ct to succeed, got exit code %d", code)
+		}
+		if want := "feat: Add tests"; stdout != want {
+			t.Errorf("expected %q on stdout, got %q", want, stdout)
+		}
+	})
+}
@@ -0,0 +1,107 @@
+/*
+Copyright © 2023 NAME HERE <EMAIL ADDRESS>
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/iamlucasvieira/ComTemplate/pkg/cli"
+)
+
+var reportFormat string
+
+// validateCmd represents the validate command
+var validateCmd = &cobra.Command{
+	Use:     "validate [file...]",
+	Aliases: []string{"lint-config"},
+	Short:   "Validates template files",
+	Long: `Checks template files for YAML errors and invalid templates and exits
+    with a non-zero status when any problem is found.
+
+    Files and directories can be given as arguments. Without arguments, the
+    file given by --config or COMTEMPLATE_CONFIG is checked, or else every
+    discovered template file.
+
+    Use '--format json' or '--format sarif' to get machine-readable output,
+    for example to show problems as annotations in code review.`,
+	PreRunE: func(cmd *cobra.Command, args []string) error {
+		_, err := cli.FormatReport(nil, reportFormat)
+		return err
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		files := validateFiles(args)
+
+		var errs cli.ValidationErrors
+		for _, file := range files {
+			errs = append(errs, cli.Validate(file)...)
+		}
+
+		if reportFormat == cli.FormatText && len(errs) == 0 {
+			cli.Write(
+				cli.TextHighlight(fmt.Sprintf("✔ %d template file(s) valid", len(files))),
+			)
+			return
+		}
+
+		report, err := cli.FormatReport(errs, reportFormat)
+		if err != nil {
+			cli.Write(
+				cli.Header("Error formatting report"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+
+		fmt.Print(report)
+
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// validateFiles returns the files to validate: the given arguments, the
+// configured file or the discovered files
+func validateFiles(args []string) []string {
+	paths := args
+	if len(paths) == 0 {
+		if path := configPath(); path != "" {
+			paths = []string{path}
+		}
+	}
+
+	if len(paths) == 0 {
+		files, err := cli.ConfigFiles()
+		if err != nil {
+			cli.Write(
+				cli.Header("Error finding template files"),
+				err.Error(),
+			)
+			os.Exit(1)
+		}
+		if len(files) == 0 {
+			cli.Write(
+				cli.Header("No template file found"),
+				"Run: 'ct init' to create a default file.",
+			)
+			os.Exit(1)
+		}
+		return files
+	}
+
+	files := make([]string, len(paths))
+	for i, path := range paths {
+		files[i] = cli.ResolveFile(path)
+	}
+	return files
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+
+	validateCmd.Flags().StringVarP(&reportFormat, "format", "f", cli.FormatText, "Output format: text, json or sarif")
+}
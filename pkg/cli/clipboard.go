@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/aymanbagabas/go-osc52/v2"
+)
+
+// Clipboard backends that can be selected by name
+const (
+	ClipboardAuto   = "auto"
+	ClipboardNative = "native"
+	ClipboardOSC52  = "osc52"
+	ClipboardTmux   = "tmux"
+	ClipboardFile   = "file"
+)
+
+// Clipboard copies text somewhere it can be pasted from
+type Clipboard interface {
+	// Copy copies text to the clipboard
+	Copy(text string) error
+	// String describes where the text is copied to
+	String() string
+}
+
+// nativeClipboard uses the system clipboard through xclip, xsel, wl-copy,
+// pbcopy or the Windows API
+type nativeClipboard struct{}
+
+func (nativeClipboard) Copy(text string) error {
+	return clipboard.WriteAll(text)
+}
+
+func (nativeClipboard) String() string {
+	return "clipboard"
+}
+
+// osc52Clipboard asks the terminal to set the clipboard with an OSC52 escape
+// sequence, which also works over SSH
+type osc52Clipboard struct {
+	tmux   bool
+	screen bool
+}
+
+func (c osc52Clipboard) Copy(text string) error {
+	seq := osc52.New(text)
+	if c.tmux {
+		seq = seq.Tmux()
+	} else if c.screen {
+		seq = seq.Screen()
+	}
+
+	var w io.Writer = os.Stderr
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		defer tty.Close()
+		w = tty
+	}
+
+	_, err := seq.WriteTo(w)
+	return err
+}
+
+func (osc52Clipboard) String() string {
+	return "terminal clipboard (OSC52)"
+}
+
+// tmuxClipboard loads the text into a tmux paste buffer
+type tmuxClipboard struct{}
+
+func (tmuxClipboard) Copy(text string) error {
+	cmd := exec.Command("tmux", "load-buffer", "-")
+	cmd.Stdin = strings.NewReader(text)
+
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if message := strings.TrimSpace(string(output)); message != "" {
+			return fmt.Errorf("%s", message)
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (tmuxClipboard) String() string {
+	return "tmux buffer"
+}
+
+// fileClipboard writes the text to a file when no clipboard is available
+type fileClipboard struct {
+	path string
+}
+
+func (c fileClipboard) Copy(text string) error {
+	return writeMessage(c.path, text)
+}
+
+func (c fileClipboard) String() string {
+	return c.path
+}
+
+// clipboardFilePath returns the path of the file used by the file backend
+func clipboardFilePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+
+	return filepath.Join(dir, "comtemplate", "clipboard.txt")
+}
+
+// detectClipboard picks a clipboard backend from the environment. Over SSH
+// the terminal clipboard is preferred, since the native clipboard would be the
+// one of the remote machine. When needsDisplay is true, as on Linux, the
+// native clipboard also needs an X11 or Wayland display, which headless
+// machines lack even with xclip installed.
+func detectClipboard(getenv func(string) string, nativeSupported, needsDisplay, terminal bool) Clipboard {
+	remote := getenv("SSH_TTY") != "" || getenv("SSH_CONNECTION") != ""
+	tmux := getenv("TMUX") != ""
+	screen := strings.HasPrefix(getenv("TERM"), "screen") && !tmux
+
+	if needsDisplay && getenv("DISPLAY") == "" && getenv("WAYLAND_DISPLAY") == "" {
+		nativeSupported = false
+	}
+
+	switch {
+	case !remote && nativeSupported:
+		return nativeClipboard{}
+	case remote && terminal:
+		return osc52Clipboard{tmux: tmux, screen: screen}
+	case tmux:
+		return tmuxClipboard{}
+	case terminal:
+		return osc52Clipboard{screen: screen}
+	default:
+		return fileClipboard{path: clipboardFilePath()}
+	}
+}
+
+// NewClipboard returns the clipboard backend with the given name. The 'auto'
+// backend is picked from the environment.
+func NewClipboard(name string) (Clipboard, error) {
+	switch name {
+	case ClipboardAuto, "":
+		// Only macOS and Windows have a clipboard without a display server
+		needsDisplay := runtime.GOOS != "darwin" && runtime.GOOS != "windows"
+		return detectClipboard(os.Getenv, !clipboard.Unsupported, needsDisplay, IsTerminal(os.Stderr)), nil
+	case ClipboardNative:
+		return nativeClipboard{}, nil
+	case ClipboardOSC52:
+		return osc52Clipboard{tmux: os.Getenv("TMUX") != ""}, nil
+	case ClipboardTmux:
+		return tmuxClipboard{}, nil
+	case ClipboardFile:
+		return fileClipboard{path: clipboardFilePath()}, nil
+	default:
+		return nil, fmt.Errorf("unknown clipboard '%s': expected auto, native, osc52, tmux or file", name)
+	}
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectClipboard(t *testing.T) {
+	testCases := []struct {
+		description     string
+		env             map[string]string
+		nativeSupported bool
+		needsDisplay    bool
+		terminal        bool
+		want            Clipboard
+	}{
+		{
+			description:     "should use the native clipboard locally",
+			nativeSupported: true,
+			terminal:        true,
+			want:            nativeClipboard{},
+		},
+		{
+			description:     "should use the native clipboard with a display",
+			env:             map[string]string{"WAYLAND_DISPLAY": "wayland-0"},
+			nativeSupported: true,
+			needsDisplay:    true,
+			terminal:        true,
+			want:            nativeClipboard{},
+		},
+		{
+			description:     "should skip the native clipboard without display",
+			nativeSupported: true,
+			needsDisplay:    true,
+			terminal:        true,
+			want:            osc52Clipboard{},
+		},
+		{
+			description:     "should fall back to a file without display nor terminal",
+			nativeSupported: true,
+			needsDisplay:    true,
+			want:            fileClipboard{path: clipboardFilePath()},
+		},
+		{
+			description:     "should use OSC52 over SSH",
+			env:             map[string]string{"SSH_TTY": "/dev/pts/0"},
+			nativeSupported: true,
+			terminal:        true,
+			want:            osc52Clipboard{},
+		},
+		{
+			description: "should wrap OSC52 for tmux over SSH",
+			env:         map[string]string{"SSH_CONNECTION": "1 2 3 4", "TMUX": "/tmp/tmux"},
+			terminal:    true,
+			want:        osc52Clipboard{tmux: true},
+		},
+		{
+			description: "should use the tmux buffer without native clipboard",
+			env:         map[string]string{"TMUX": "/tmp/tmux"},
+			terminal:    true,
+			want:        tmuxClipboard{},
+		},
+		{
+			description: "should use OSC52 in a terminal without native clipboard",
+			terminal:    true,
+			want:        osc52Clipboard{},
+		},
+		{
+			description: "should fall back to a file without terminal",
+			env:         map[string]string{"SSH_TTY": "/dev/pts/0"},
+			want:        fileClipboard{path: clipboardFilePath()},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			getenv := func(key string) string { return tc.env[key] }
+
+			got := detectClipboard(getenv, tc.nativeSupported, tc.needsDisplay, tc.terminal)
+			if got != tc.want {
+				t.Errorf("expected %#v, got %#v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNewClipboard(t *testing.T) {
+	for _, name := range []string{ClipboardAuto, ClipboardNative, ClipboardOSC52, ClipboardTmux, ClipboardFile} {
+		if _, err := NewClipboard(name); err != nil {
+			t.Errorf("error creating clipboard %s: %v", name, err)
+		}
+	}
+
+	if _, err := NewClipboard("printer"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestFileClipboard(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "testDir")
+	if err != nil {
+		t.Fatalf("error creating temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	c := fileClipboard{path: filepath.Join(tempDir, "comtemplate", "clipboard.txt")}
+
+	message, err := Output{Kind: OutputClipboard, Clipboard: c}.Send("Test title\n")
+	if err != nil {
+		t.Fatalf("error copying: %v", err)
+	}
+
+	if message != "Copied to "+c.path {
+		t.Errorf("expected message to name the file, got '%s'", message)
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		t.Fatalf("error reading file: %v", err)
+	}
+
+	if string(data) != "Test title\n" {
+		t.Errorf("expected file to contain 'Test title\n', got '%s'", data)
+	}
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// computed reports whether the value of the variable is computed from other
+// variables instead of being asked for
+func (v Variable) computed() bool {
+	return v.Type == "computed"
+}
+
+// dependencies returns the names of the variables the value of a computed
+// variable is computed from
+func (v Variable) dependencies() []string {
+	if v.From != "" {
+		return []string{v.From}
+	}
+
+	nodes, err := parseText(v.Value)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, name := range referencedNames(nodes) {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// mapValues returns the values a computed variable with a map can take,
+// sorted
+func (v Variable) mapValues() []string {
+	var values []string
+	for _, value := range v.Map {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	sort.Strings(values)
+	return values
+}
+
+// computeValue returns the value of a computed variable given the values of
+// the variables it depends on
+func (v Variable) computeValue(values map[string]string) string {
+	if v.From != "" {
+		if value, ok := v.Map[values[v.From]]; ok {
+			return value
+		}
+		return v.Default
+	}
+
+	nodes, err := parseText(v.Value)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(render(nodes, values))
+}
+
+// computeOrder returns the computed variables of variables ordered so that
+// each one comes after the computed variables it depends on. It returns an
+// error describing the cycle when computed variables depend on each other.
+func computeOrder(variables []Variable) ([]Variable, error) {
+	byName := make(map[string]Variable)
+	for _, variable := range variables {
+		if variable.computed() {
+			byName[variable.Name] = variable
+		}
+	}
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+
+	var order []Variable
+	var path []string
+
+	var visit func(variable Variable) error
+	visit = func(variable Variable) error {
+		switch state[variable.Name] {
+		case done:
+			return nil
+		case visiting:
+			start := slices.Index(path, variable.Name)
+			cycle := append(slices.Clone(path[start:]), variable.Name)
+			return fmt.Errorf("computed variables depend on each other: %s", strings.Join(cycle, " -> "))
+		}
+
+		state[variable.Name] = visiting
+		path = append(path, variable.Name)
+
+		for _, name := range variable.dependencies() {
+			if dependency, ok := byName[name]; ok {
+				if err := visit(dependency); err != nil {
+					return err
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[variable.Name] = done
+		order = append(order, variable)
+		return nil
+	}
+
+	for _, variable := range variables {
+		if !variable.computed() {
+			continue
+		}
+		if err := visit(variable); err != nil {
+			return nil, err
+		}
+	}
+
+	return order, nil
+}
+
+// computeValues sets the values of the computed variables of template in
+// values, which must hold the values of the other variables
+func computeValues(template Template, values map[string]string) error {
+	order, err := computeOrder(template.Variables)
+	if err != nil {
+		return err
+	}
+
+	for _, variable := range order {
+		values[variable.Name] = variable.computeValue(values)
+	}
+
+	return nil
+}
+
+// validateComputed adds the problems of the settings of computed variables
+// to errs
+func (v Variable) validateComputed(TemplateId, VarId int, errs *ValidationErrors) {
+	if !v.computed() {
+		if v.Value != "" || v.From != "" || len(v.Map) > 0 {
+			errs.add(TemplateId, VarId, "type", v.line, "variable %s has value, from or map but is not computed", v.Name)
+		}
+		return
+	}
+
+	switch {
+	case v.Value == "" && v.From == "":
+		errs.add(TemplateId, VarId, "value", v.line, "computed variable %s has neither value nor from", v.Name)
+	case v.Value != "" && v.From != "":
+		errs.add(TemplateId, VarId, "value", v.line, "computed variable %s has both value and from", v.Name)
+	case v.From != "" && len(v.Map) == 0:
+		errs.add(TemplateId, VarId, "map", v.line, "computed variable %s has from but no map", v.Name)
+	case v.From == "" && len(v.Map) > 0:
+		errs.add(TemplateId, VarId, "map", v.line, "computed variable %s has a map but no from", v.Name)
+	}
+
+	if _, err := parseText(v.Value); err != nil {
+		errs.add(TemplateId, VarId, "value", v.line, "computed variable %s has invalid value: %v", v.Name, err)
+	}
+
+	for _, setting := range [][2]string{
+		{"options", strings.Join(v.Options, "")},
+		{"source", v.Source},
+		{"options_from", v.OptionsFrom},
+		{"options_command", v.OptionsCommand},
+		{"options_file", v.OptionsFile},
+	} {
+		if setting[1] != "" {
+			errs.add(TemplateId, VarId, setting[0], v.line, "computed variable %s cannot have %s", v.Name, setting[0])
+		}
+	}
+}
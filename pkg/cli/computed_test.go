@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestComputeOrder(t *testing.T) {
+	variables := []Variable{
+		{Name: "refs", Type: "computed", Value: "Refs: %{link}"},
+		{Name: "link", Type: "computed", Value: "https://jira.example.com/browse/%{ticket}"},
+		{Name: "ticket"},
+	}
+
+	order, err := computeOrder(variables)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, variable := range order {
+		names = append(names, variable.Name)
+	}
+	if got := strings.Join(names, ","); got != "link,refs" {
+		t.Errorf("expected link before refs, got %s", got)
+	}
+
+	variables = append(variables, Variable{Name: "loop", Type: "computed", From: "other", Map: map[string]string{"a": "b"}})
+	variables = append(variables, Variable{Name: "other", Type: "computed", Value: "%{loop}"})
+
+	_, err = computeOrder(variables)
+	if err == nil || !strings.Contains(err.Error(), "loop -> other -> loop") {
+		t.Errorf("expected cycle error, got %v", err)
+	}
+}
+
+func TestPopulateTemplateComputed(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "%{emoji} %{type}: %{title}\n\n%{?refs}%{refs}%{/refs}\n",
+		Variables: []Variable{
+			{Name: "emoji", Type: "computed", From: "type", Map: map[string]string{"feat": "✨", "fix": "🐛"}, Default: "🔧"},
+			{Name: "type", Type: "select", Options: []string{"feat", "fix", "chore"}},
+			{Name: "title"},
+			{Name: "ticket"},
+			{Name: "refs", Type: "computed", Value: "%{?ticket}Refs: %{ticket|upper}%{/ticket}"},
+		},
+	}
+
+	if err := template.validate(0); err != nil {
+		t.Fatalf("unexpected validation error: %v", err)
+	}
+
+	testCases := []struct {
+		description string
+		values      map[string]string
+		want        string
+	}{
+		{
+			description: "should compute from the answers",
+			values:      map[string]string{"type": "feat", "title": "Add login", "ticket": "proj-1"},
+			want:        "✨ feat: Add login\n\nRefs: PROJ-1\n",
+		},
+		{
+			description: "should fall back to the default and drop empty values",
+			values:      map[string]string{"type": "chore", "title": "Bump deps", "ticket": ""},
+			want:        "🔧 chore: Bump deps\n",
+		},
+		{
+			description: "should ignore given values",
+			values:      map[string]string{"type": "fix", "title": "Fix crash", "ticket": "", "emoji": "🔥"},
+			want:        "🐛 fix: Fix crash\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := PopulateTemplate(template, tc.values)
+			if err != nil {
+				t.Fatalf("error populating template: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+
+	if missing := MissingVariables(template, map[string]string{"type": "feat", "title": "x", "ticket": ""}); len(missing) != 0 {
+		t.Errorf("expected computed variables not to be asked for, got %v", missing)
+	}
+
+	match, err := MatchMessage([]Template{template}, "🐛 fix: Fix crash\n\nRefs: PROJ-1\n")
+	if err != nil {
+		t.Fatalf("error matching message: %v", err)
+	}
+	if !match.OK {
+		t.Errorf("expected message to match, got %s", match)
+	}
+}
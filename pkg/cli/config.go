@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// defaultNames are the names of template files looked up in each directory
+var defaultNames = []string{
+	"comtemplate.yml",
+	"comtemplate.yaml",
+}
+
+// ConfigEnv is the environment variable pointing at an explicit template file
+// or directory
+const ConfigEnv = "COMTEMPLATE_CONFIG"
+
+// systemConfigDir is the directory holding the system wide template file
+var systemConfigDir = "/etc/comtemplate"
+
+// userConfigDir returns the directory holding the user template file
+func userConfigDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "comtemplate")
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".config", "comtemplate")
+}
+
+// findGitRoot returns the closest directory from dir upwards that is the root
+// of a git repository, or an empty string when dir is not in a repository
+func findGitRoot(dir string) string {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// SearchDirs returns the directories searched for template files, closest
+// first: the current directory and its parents up to the git root, the user
+// config directory and the system config directory
+func SearchDirs() ([]string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("error getting working directory: %v", err)
+	}
+
+	dirs := []string{cwd}
+
+	if root := findGitRoot(cwd); root != "" {
+		for dir := cwd; dir != root; {
+			dir = filepath.Dir(dir)
+			dirs = append(dirs, dir)
+		}
+	}
+
+	if dir := userConfigDir(); dir != "" {
+		dirs = append(dirs, dir)
+	}
+
+	return append(dirs, systemConfigDir), nil
+}
+
+// findFile returns the template file in dir, or an empty string if there is
+// none
+func findFile(dir string) string {
+	for _, name := range defaultNames {
+		path := filepath.Join(dir, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// ResolveFile returns the template file inside path when path is a directory
+// holding one, and path itself otherwise
+func ResolveFile(path string) string {
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		if file := findFile(path); file != "" {
+			return file
+		}
+	}
+
+	return path
+}
+
+// ConfigFiles returns the template files found in the search directories,
+// closest first
+func ConfigFiles() ([]string, error) {
+	dirs, err := SearchDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, dir := range dirs {
+		if path := findFile(dir); path != "" {
+			files = append(files, path)
+		}
+	}
+
+	return files, nil
+}
+
+// merge merges lists of templates ordered from closest to farthest. When
+// names collide, the closest template wins.
+func merge(levels ...[]Template) []Template {
+	seen := make(map[string]bool)
+	var merged []Template
+
+	for _, templates := range levels {
+		for _, template := range templates {
+			if seen[template.Name] {
+				continue
+			}
+			seen[template.Name] = true
+			merged = append(merged, template)
+		}
+	}
+
+	return merged
+}
+
+// readAll reads and merges the templates of the given files, ordered from
+// closest to farthest. Invalid templates of every file are reported together
+// as ValidationErrors.
+func readAll(files []string) ([]Template, error) {
+	var levels [][]Template
+	var errs ValidationErrors
+
+	for _, path := range files {
+		templates, err := read(path)
+		if validationErrs, ok := err.(ValidationErrors); ok {
+			errs = append(errs, validationErrs...)
+		} else if err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+		levels = append(levels, templates)
+	}
+
+	return merge(levels...), errs.orNil()
+}
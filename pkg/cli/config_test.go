@@ -0,0 +1,177 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// writeFile writes data to path, creating its parent directories
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		t.Fatalf("error creating directory: %v", err)
+	}
+
+	err = os.WriteFile(path, []byte(data), 0644)
+	if err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	closest := []Template{{Name: "a", Source: "closest"}, {Name: "b", Source: "closest"}}
+	farthest := []Template{{Name: "b", Source: "farthest"}, {Name: "c", Source: "farthest"}}
+
+	merged := merge(closest, farthest)
+
+	t.Run("should keep every name once", func(t *testing.T) {
+		if len(merged) != 3 {
+			t.Errorf("expected three templates, got %d", len(merged))
+		}
+	})
+
+	t.Run("should prefer the closest template", func(t *testing.T) {
+		for _, template := range merged {
+			if template.Name == "b" && template.Source != "closest" {
+				t.Errorf("expected template b from closest, got %s", template.Source)
+			}
+		}
+	})
+}
+
+func TestSearchDirs(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "testDir")
+	if err != nil {
+		t.Fatalf("error creating temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// Resolve symlinks so paths match the working directory
+	tempDir, err = filepath.EvalSymlinks(tempDir)
+	if err != nil {
+		t.Fatalf("error resolving temp directory: %v", err)
+	}
+
+	repo := filepath.Join(tempDir, "repo")
+	sub := filepath.Join(repo, "a", "b")
+	err = os.MkdirAll(filepath.Join(repo, ".git"), 0755)
+	if err != nil {
+		t.Fatalf("error creating repository: %v", err)
+	}
+	err = os.MkdirAll(sub, 0755)
+	if err != nil {
+		t.Fatalf("error creating directory: %v", err)
+	}
+
+	userDir := filepath.Join(tempDir, "config")
+	t.Setenv("XDG_CONFIG_HOME", userDir)
+
+	originalSystemDir := systemConfigDir
+	systemConfigDir = filepath.Join(tempDir, "etc")
+	defer func() { systemConfigDir = originalSystemDir }()
+
+	err = os.Chdir(sub)
+	if err != nil {
+		t.Fatalf("error changing working directory: %v", err)
+	}
+
+	t.Run("should walk up to the git root", func(t *testing.T) {
+		dirs, err := SearchDirs()
+		if err != nil {
+			t.Fatalf("error getting search directories: %v", err)
+		}
+
+		want := []string{
+			sub,
+			filepath.Join(repo, "a"),
+			repo,
+			filepath.Join(userDir, "comtemplate"),
+			systemConfigDir,
+		}
+
+		if !slices.Equal(dirs, want) {
+			t.Errorf("expected directories %v, got %v", want, dirs)
+		}
+	})
+
+	t.Run("should merge templates from every level", func(t *testing.T) {
+		writeFile(t, filepath.Join(repo, "comtemplate.yml"), `
+- name: shared
+  description: repository
+  text: "%{title}"
+  variables:
+    - name: title
+`)
+		writeFile(t, filepath.Join(userDir, "comtemplate", "comtemplate.yaml"), `
+- name: shared
+  description: user
+  text: "%{title}"
+  variables:
+    - name: title
+- name: personal
+  text: "%{title}"
+  variables:
+    - name: title
+`)
+
+		templates, err := ReadDefault()
+		if err != nil {
+			t.Fatalf("error reading templates: %v", err)
+		}
+
+		if len(templates) != 2 {
+			t.Fatalf("expected two templates, got %d", len(templates))
+		}
+
+		if templates[0].Description != "repository" {
+			t.Errorf("expected the repository template to win, got '%s'", templates[0].Description)
+		}
+
+		if templates[1].Source != filepath.Join(userDir, "comtemplate", "comtemplate.yaml") {
+			t.Errorf("expected source to be the user file, got '%s'", templates[1].Source)
+		}
+	})
+}
+
+func TestReadPath(t *testing.T) {
+	tempDir, err := os.MkdirTemp("", "testDir")
+	if err != nil {
+		t.Fatalf("error creating temp directory: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	path := filepath.Join(tempDir, "tools", "commit", "comtemplate.yaml")
+	writeFile(t, path, mockData)
+
+	t.Run("should read a file", func(t *testing.T) {
+		templates, err := Read(path)
+		if err != nil {
+			t.Fatalf("error reading file: %v", err)
+		}
+
+		if len(templates) != 2 {
+			t.Errorf("expected two templates, got %d", len(templates))
+		}
+	})
+
+	t.Run("should read the template file in a directory", func(t *testing.T) {
+		templates, err := Read(filepath.Dir(path))
+		if err != nil {
+			t.Fatalf("error reading directory: %v", err)
+		}
+
+		if templates[0].Source != path {
+			t.Errorf("expected source to be '%s', got '%s'", path, templates[0].Source)
+		}
+	})
+
+	t.Run("should fail for a directory without template file", func(t *testing.T) {
+		if _, err := Read(tempDir); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
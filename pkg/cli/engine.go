@@ -0,0 +1,406 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Template text is made of literal text and placeholders:
+//
+//	%{name}                    value of the variable name
+//	%{name|lower|truncate:50}  value passed through filters, left to right
+//	%{?name}...%{/name}        block dropped when name is empty
+//	%%{                        literal %{
+//
+// The filters are lower, upper, trim, truncate:<length> and wrap:<width>.
+
+// nodeKind is the kind of a node of parsed template text
+type nodeKind int
+
+const (
+	// textNode is literal text
+	textNode nodeKind = iota
+	// varNode is a %{name} placeholder
+	varNode
+	// blockNode is a %{?name}...%{/name} block, dropped when name is empty
+	blockNode
+)
+
+// node is a piece of parsed template text
+type node struct {
+	kind nodeKind
+	// text is the literal text of text nodes and the source of placeholders
+	text     string
+	name     string
+	filters  []filter
+	children []node
+	// line and col locate the node in the template text, starting at 1
+	line int
+	col  int
+}
+
+// ParseError is an error in template text
+type ParseError struct {
+	Line    int
+	Col     int
+	Message string
+}
+
+func (e ParseError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Col, e.Message)
+}
+
+// filter is a filter applied to the value of a placeholder
+type filter struct {
+	name string
+	args []string
+	fn   func(value string, args []string) string
+}
+
+// filterSpec describes a filter that can be used in placeholders
+type filterSpec struct {
+	// args is the number of arguments of the filter, all integers
+	args int
+	fn   func(value string, args []string) string
+}
+
+// filters are the filters available in placeholders
+var filters = map[string]filterSpec{
+	"lower": {fn: func(value string, _ []string) string { return strings.ToLower(value) }},
+	"upper": {fn: func(value string, _ []string) string { return strings.ToUpper(value) }},
+	"trim":  {fn: func(value string, _ []string) string { return strings.TrimSpace(value) }},
+	"truncate": {args: 1, fn: func(value string, args []string) string {
+		n, _ := strconv.Atoi(args[0])
+		return truncate(value, n)
+	}},
+	"wrap": {args: 1, fn: func(value string, args []string) string {
+		n, _ := strconv.Atoi(args[0])
+		return wrap(value, n)
+	}},
+}
+
+// truncate cuts s to at most n characters
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// wrap wraps the lines of s at width characters, breaking between words.
+// Words longer than width are left on a line of their own.
+func wrap(s string, width int) string {
+	var lines []string
+
+	for _, line := range strings.Split(s, "\n") {
+		words := strings.Fields(line)
+		if len(words) == 0 {
+			lines = append(lines, "")
+			continue
+		}
+
+		current := words[0]
+		for _, word := range words[1:] {
+			if utf8.RuneCountInString(current)+1+utf8.RuneCountInString(word) > width {
+				lines = append(lines, current)
+				current = word
+				continue
+			}
+			current += " " + word
+		}
+		lines = append(lines, current)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// lexer walks template text keeping track of the position
+type lexer struct {
+	text string
+	pos  int
+	line int
+	col  int
+}
+
+// errorf returns a parse error at the given position
+func errorf(line, col int, format string, args ...any) error {
+	return ParseError{Line: line, Col: col, Message: fmt.Sprintf(format, args...)}
+}
+
+// advance moves the lexer n bytes forward
+func (l *lexer) advance(n int) {
+	for _, r := range l.text[l.pos : l.pos+n] {
+		if r == '\n' {
+			l.line++
+			l.col = 1
+		} else {
+			l.col++
+		}
+	}
+	l.pos += n
+}
+
+// rest returns the text not read yet
+func (l *lexer) rest() string {
+	return l.text[l.pos:]
+}
+
+// readText reads literal text up to the next placeholder
+func (l *lexer) readText() string {
+	var builder strings.Builder
+
+	for l.pos < len(l.text) {
+		rest := l.rest()
+		switch {
+		case strings.HasPrefix(rest, "%%{"):
+			builder.WriteString("%{")
+			l.advance(3)
+		case strings.HasPrefix(rest, "%{"):
+			return builder.String()
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			builder.WriteString(rest[:size])
+			l.advance(size)
+		}
+	}
+
+	return builder.String()
+}
+
+// readPlaceholder reads a placeholder starting at %{ and returns its content
+// split on pipes
+func (l *lexer) readPlaceholder() ([]string, error) {
+	line, col := l.line, l.col
+	l.advance(2)
+
+	end := strings.IndexAny(l.rest(), "}\n")
+	if end < 0 || l.rest()[end] == '\n' {
+		return nil, errorf(line, col, "placeholder is not closed")
+	}
+
+	content := l.rest()[:end]
+	l.advance(end + 1)
+
+	parts := strings.Split(content, "|")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+
+	if parts[0] == "" {
+		return nil, errorf(line, col, "placeholder has no variable name")
+	}
+
+	return parts, nil
+}
+
+// parseFilter parses a filter written as name or name:arg
+func parseFilter(s string, line, col int) (filter, error) {
+	name, arg, hasArg := strings.Cut(s, ":")
+	name = strings.TrimSpace(name)
+
+	spec, ok := filters[name]
+	if !ok {
+		return filter{}, errorf(line, col, "unknown filter '%s'", name)
+	}
+
+	var args []string
+	if hasArg {
+		args = strings.Split(arg, ",")
+	}
+
+	if len(args) != spec.args {
+		return filter{}, errorf(line, col, "filter '%s' takes %d argument(s), got %d", name, spec.args, len(args))
+	}
+
+	for i, arg := range args {
+		arg = strings.TrimSpace(arg)
+		n, err := strconv.Atoi(arg)
+		if err != nil || n < 0 {
+			return filter{}, errorf(line, col, "filter '%s' expects a positive number, got '%s'", name, arg)
+		}
+		args[i] = arg
+	}
+
+	return filter{name: name, args: args, fn: spec.fn}, nil
+}
+
+// parseText parses template text into a list of nodes
+func parseText(text string) ([]node, error) {
+	l := &lexer{text: text, line: 1, col: 1}
+
+	// The root of the tree is a block without name
+	stack := []*node{{kind: blockNode}}
+
+	for l.pos < len(l.text) {
+		current := stack[len(stack)-1]
+		start, line, col := l.pos, l.line, l.col
+
+		if !strings.HasPrefix(l.rest(), "%{") {
+			literal := l.readText()
+			current.children = append(current.children, node{kind: textNode, text: literal, line: line, col: col})
+			continue
+		}
+
+		parts, err := l.readPlaceholder()
+		if err != nil {
+			return nil, err
+		}
+
+		content := parts[0]
+		switch {
+		case strings.HasPrefix(content, "?"), strings.HasPrefix(content, "/"):
+			if len(parts) > 1 {
+				return nil, errorf(line, col, "block %s cannot have filters", content)
+			}
+
+			name := strings.TrimSpace(content[1:])
+			if name == "" {
+				return nil, errorf(line, col, "block has no variable name")
+			}
+
+			if content[0] == '?' {
+				stack = append(stack, &node{kind: blockNode, name: name, line: line, col: col})
+				continue
+			}
+
+			if len(stack) == 1 {
+				return nil, errorf(line, col, "block %s is closed but was never opened", name)
+			}
+			if current.name != name {
+				return nil, errorf(line, col, "block %s is closed by %%{/%s}", current.name, name)
+			}
+			stack = stack[:len(stack)-1]
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, *current)
+		default:
+			if strings.IndexFunc(content, unicode.IsSpace) >= 0 {
+				return nil, errorf(line, col, "variable name '%s' contains spaces", content)
+			}
+
+			n := node{kind: varNode, text: l.text[start:l.pos], name: content, line: line, col: col}
+			for _, part := range parts[1:] {
+				f, err := parseFilter(part, line, col)
+				if err != nil {
+					return nil, err
+				}
+				n.filters = append(n.filters, f)
+			}
+			current.children = append(current.children, n)
+		}
+	}
+
+	if len(stack) > 1 {
+		open := stack[len(stack)-1]
+		return nil, errorf(open.line, open.col, "block %s is not closed", open.name)
+	}
+
+	return stack[0].children, nil
+}
+
+// placeholders returns the placeholder nodes of nodes, including the ones in
+// blocks
+func placeholders(nodes []node) []node {
+	var found []node
+
+	for _, n := range nodes {
+		switch n.kind {
+		case varNode:
+			found = append(found, n)
+		case blockNode:
+			found = append(found, node{kind: varNode, text: "%{?" + n.name + "}", name: n.name, line: n.line, col: n.col})
+			found = append(found, placeholders(n.children)...)
+		}
+	}
+
+	return found
+}
+
+// referencedNames returns the names of the variables used by nodes, either as
+// placeholders or as block conditions
+func referencedNames(nodes []node) []string {
+	var names []string
+
+	for _, n := range placeholders(nodes) {
+		names = append(names, n.name)
+	}
+
+	return names
+}
+
+// unguardedNames adds to names the variables used by nodes outside a block of
+// their own, given the names of the blocks nodes are in
+func unguardedNames(nodes []node, blocks []string, names map[string]bool) {
+	for _, n := range nodes {
+		switch n.kind {
+		case varNode:
+			if !slices.Contains(blocks, n.name) {
+				names[n.name] = true
+			}
+		case blockNode:
+			unguardedNames(n.children, append(slices.Clone(blocks), n.name), names)
+		}
+	}
+}
+
+// render renders nodes with values. Placeholders without a value are kept as
+// they are written.
+func render(nodes []node, values map[string]string) string {
+	var builder strings.Builder
+
+	for _, n := range nodes {
+		switch n.kind {
+		case textNode:
+			builder.WriteString(n.text)
+		case varNode:
+			value, ok := values[n.name]
+			if !ok {
+				builder.WriteString(n.text)
+				continue
+			}
+			for _, f := range n.filters {
+				value = f.fn(value, f.args)
+			}
+			builder.WriteString(value)
+		case blockNode:
+			if strings.TrimSpace(values[n.name]) != "" {
+				builder.WriteString(render(n.children, values))
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+// normalizeBlankLines removes leading and trailing blank lines and collapses
+// consecutive blank lines into one. A final line break is kept when s has one.
+func normalizeBlankLines(s string) string {
+	var lines []string
+	blank := true
+
+	for _, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	result := strings.Join(lines, "\n")
+	if strings.HasSuffix(s, "\n") && result != "" {
+		result += "\n"
+	}
+
+	return result
+}
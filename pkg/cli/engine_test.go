@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"testing"
+)
+
+func TestParseText(t *testing.T) {
+	testCases := []struct {
+		description string
+		text        string
+		wantErr     bool
+	}{
+		{description: "should parse plain text", text: "Test"},
+		{description: "should parse placeholders", text: "[%{type}] %{title}"},
+		{description: "should parse nested blocks", text: "%{?body}%{?footer}%{footer}%{/footer}%{/body}"},
+		{description: "should fail for unclosed placeholder", text: "Test %{title", wantErr: true},
+		{description: "should fail for unclosed block", text: "%{?body}%{body}", wantErr: true},
+		{description: "should fail for unopened block", text: "%{body}%{/body}", wantErr: true},
+		{description: "should fail for mismatched block", text: "%{?body}%{?footer}%{/body}%{/footer}", wantErr: true},
+		{description: "should parse filters", text: "%{scope|lower} %{title | trim | truncate:50}"},
+		{description: "should fail for unknown filter", text: "%{scope|reverse}", wantErr: true},
+		{description: "should fail for missing filter argument", text: "%{body|wrap}", wantErr: true},
+		{description: "should fail for invalid filter argument", text: "%{body|wrap:wide}", wantErr: true},
+		{description: "should fail for filters on blocks", text: "%{?body|lower}%{/body}", wantErr: true},
+		{description: "should fail for empty placeholder", text: "%{}", wantErr: true},
+		{description: "should parse escaped placeholders", text: "100%%{done}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			_, err := parseText(tc.text)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseErrorPosition(t *testing.T) {
+	testCases := []struct {
+		text string
+		line int
+		col  int
+	}{
+		{text: "title\n\n  %{body|nope}", line: 3, col: 3},
+		{text: "✨ %{title", line: 1, col: 3},
+		{text: "%{title}\n%{?body}\n%{body}", line: 2, col: 1},
+	}
+
+	for _, tc := range testCases {
+		_, err := parseText(tc.text)
+
+		parseErr, ok := err.(ParseError)
+		if !ok {
+			t.Errorf("parsing %q: expected ParseError, got %v", tc.text, err)
+			continue
+		}
+
+		if parseErr.Line != tc.line || parseErr.Col != tc.col {
+			t.Errorf("parsing %q: expected error at %d:%d, got %d:%d", tc.text, tc.line, tc.col, parseErr.Line, parseErr.Col)
+		}
+	}
+}
+
+func TestRenderFilters(t *testing.T) {
+	values := map[string]string{
+		"scope":  "API",
+		"title":  "  a very long title  ",
+		"ticket": "proj-12",
+		"body":   "one two three four five",
+	}
+
+	testCases := []struct {
+		text string
+		want string
+	}{
+		{text: "%{scope|lower}", want: "api"},
+		{text: "%{ticket|upper}", want: "PROJ-12"},
+		{text: "%{title|trim|truncate:6}", want: "a very"},
+		{text: "%{body|wrap:9}", want: "one two\nthree\nfour five"},
+		{text: "100%%{scope}", want: "100%{scope}"},
+		{text: "%{missing|upper}", want: "%{missing|upper}"},
+	}
+
+	for _, tc := range testCases {
+		nodes, err := parseText(tc.text)
+		if err != nil {
+			t.Errorf("parsing %q: %v", tc.text, err)
+			continue
+		}
+
+		if got := render(nodes, values); got != tc.want {
+			t.Errorf("rendering %q: expected %q, got %q", tc.text, tc.want, got)
+		}
+	}
+}
+
+func TestReferencedNames(t *testing.T) {
+	nodes, err := parseText("%{title}%{?breaking}BREAKING CHANGE: %{description}%{/breaking}")
+	if err != nil {
+		t.Fatalf("error parsing text: %v", err)
+	}
+
+	names := referencedNames(nodes)
+	want := []string{"title", "breaking", "description"}
+
+	if len(names) != len(want) {
+		t.Fatalf("expected names %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("expected names %v, got %v", want, names)
+		}
+	}
+}
+
+func TestRenderBlocks(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "%{title}\n\n%{?body}\n%{body}\n%{/body}\n\n%{?breaking}BREAKING CHANGE: %{breaking}%{/breaking}\n",
+		Variables: []Variable{
+			{Name: "title"},
+			{Name: "body"},
+			{Name: "breaking"},
+		},
+	}
+
+	testCases := []struct {
+		description string
+		values      map[string]string
+		want        string
+	}{
+		{
+			description: "should drop empty blocks",
+			values:      map[string]string{"title": "Test title", "body": "", "breaking": ""},
+			want:        "Test title\n",
+		},
+		{
+			description: "should drop blocks with blank values",
+			values:      map[string]string{"title": "Test title", "body": " \n", "breaking": ""},
+			want:        "Test title\n",
+		},
+		{
+			description: "should keep filled blocks",
+			values:      map[string]string{"title": "Test title", "body": "Test body", "breaking": "removed flag"},
+			want:        "Test title\n\nTest body\n\nBREAKING CHANGE: removed flag\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := PopulateTemplate(template, tc.values)
+			if err != nil {
+				t.Fatalf("error populating template: %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected string to be '%q', got '%q'", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeBlankLines(t *testing.T) {
+	testCases := []struct {
+		s    string
+		want string
+	}{
+		{s: "title", want: "title"},
+		{s: "title\n", want: "title\n"},
+		{s: "\n\ntitle\n\n\n\nbody\n\n\n", want: "title\n\nbody\n"},
+		{s: "title\n  \nbody", want: "title\n\nbody"},
+		{s: "\n\n", want: ""},
+	}
+
+	for _, tc := range testCases {
+		if got := normalizeBlankLines(tc.s); got != tc.want {
+			t.Errorf("normalizing %q: expected %q, got %q", tc.s, tc.want, got)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// formModel wraps a form and quits once it is completed or aborted, so that
+// the form can be drawn on any output
+type formModel struct {
+	form *huh.Form
+}
+
+func (m formModel) Init() tea.Cmd {
+	return m.form.Init()
+}
+
+func (m formModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	model, cmd := m.form.Update(msg)
+	m.form = model.(*huh.Form)
+
+	if m.form.State != huh.StateNormal {
+		return m, tea.Quit
+	}
+
+	return m, cmd
+}
+
+func (m formModel) View() string {
+	if m.form.State != huh.StateNormal {
+		return ""
+	}
+	return m.form.View()
+}
+
+// runForm runs form drawing it on the messages output
+func runForm(form *huh.Form) error {
+	_, err := tea.NewProgram(formModel{form: form}, tea.WithOutput(output)).Run()
+
+	if err != nil {
+		return err
+	}
+
+	if form.State == huh.StateAborted {
+		return huh.ErrUserAborted
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+)
+
+// CommitOptions are the git commit flags passed through when committing
+type CommitOptions struct {
+	Amend    bool
+	Signoff  bool
+	NoVerify bool
+	All      bool
+}
+
+// args returns the git commit arguments matching the options
+func (o CommitOptions) args() []string {
+	var args []string
+
+	if o.Amend {
+		args = append(args, "--amend")
+	}
+
+	if o.Signoff {
+		args = append(args, "--signoff")
+	}
+
+	if o.NoVerify {
+		args = append(args, "--no-verify")
+	}
+
+	if o.All {
+		args = append(args, "--all")
+	}
+
+	return args
+}
+
+// runGit runs git with the given arguments and returns its standard output.
+// When git fails, the returned error holds git's error output.
+func runGit(stdin io.Reader, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Stdin = stdin
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = strings.TrimSpace(stdout.String())
+		}
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("%s", message)
+	}
+
+	return stdout.String(), nil
+}
+
+// Commit creates a commit in the current repository using message as the
+// commit message and returns git's output
+func Commit(message string, opts CommitOptions) (string, error) {
+	args := append([]string{"commit", "--file", "-"}, opts.args()...)
+
+	output, err := runGit(strings.NewReader(message), args...)
+
+	if err != nil {
+		return "", fmt.Errorf("error creating commit: %v", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// CommitMessage is the message of an existing commit
+type CommitMessage struct {
+	Hash    string
+	Message string
+}
+
+// CommitMessages returns the messages of the commits in revisions, such as
+// main..HEAD, oldest first. Merge commits are skipped.
+func CommitMessages(revisions string) ([]CommitMessage, error) {
+	output, err := runGit(nil, "log", "--reverse", "--no-merges", "--format=%H%x1f%B%x1e", revisions, "--")
+	if err != nil {
+		return nil, fmt.Errorf("error reading commits: %v", err)
+	}
+
+	var commits []CommitMessage
+	for _, record := range strings.Split(output, "\x1e") {
+		hash, message, found := strings.Cut(strings.TrimLeft(record, "\n"), "\x1f")
+		if !found {
+			continue
+		}
+		commits = append(commits, CommitMessage{Hash: hash, Message: message})
+	}
+
+	return commits, nil
+}
+
+// CommentChar returns what starts comment lines in commit message files,
+// from core.commentString or core.commentChar. With "auto", git picks a
+// character per message, and the default one is returned.
+func CommentChar() string {
+	for _, key := range []string{"core.commentString", "core.commentChar"} {
+		output, err := runGit(nil, "config", "--get", key)
+		if value := strings.TrimRight(output, "\n"); err == nil && value != "" && value != "auto" {
+			return value
+		}
+	}
+	return defaultCommentChar
+}
+
+// CurrentBranch returns the name of the branch checked out in the current
+// repository. It fails when HEAD is detached.
+func CurrentBranch() (string, error) {
+	output, err := runGit(nil, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("error reading current branch: %v", err)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// StagedPaths returns the paths of the files staged in the current repository
+func StagedPaths() ([]string, error) {
+	// Paths are separated by NUL so that git neither quotes nor splits them
+	output, err := runGit(nil, "diff", "--cached", "--name-only", "-z")
+	if err != nil {
+		return nil, fmt.Errorf("error reading staged files: %v", err)
+	}
+
+	var paths []string
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// initRepo creates a temporary git repository and changes the working
+// directory to it
+func initRepo(t *testing.T) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not available")
+	}
+
+	tempDir, err := os.MkdirTemp("", "testRepo")
+	if err != nil {
+		t.Fatalf("error creating temp directory: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tempDir) })
+
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	err = os.Chdir(tempDir)
+	if err != nil {
+		t.Fatalf("error changing working directory: %v", err)
+	}
+
+	if _, err := runGit(nil, "init", "--quiet"); err != nil {
+		t.Fatalf("error initializing repository: %v", err)
+	}
+
+	return tempDir
+}
+
+func TestCommitOptionsArgs(t *testing.T) {
+	opts := CommitOptions{Amend: true, Signoff: true, NoVerify: true, All: true}
+	want := []string{"--amend", "--signoff", "--no-verify", "--all"}
+
+	if got := opts.args(); !slices.Equal(got, want) {
+		t.Errorf("expected args to be %v, got %v", want, got)
+	}
+
+	if got := (CommitOptions{}).args(); len(got) != 0 {
+		t.Errorf("expected no args, got %v", got)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	initRepo(t)
+
+	t.Run("should fail without staged changes", func(t *testing.T) {
+		_, err := Commit("Test title\n", CommitOptions{})
+
+		if err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	err := os.WriteFile("file.txt", []byte("content"), 0644)
+	if err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	if _, err := runGit(nil, "add", "file.txt"); err != nil {
+		t.Fatalf("error staging file: %v", err)
+	}
+
+	t.Run("should create a commit with the message", func(t *testing.T) {
+		_, err := Commit("Test title\n\nTest body\n", CommitOptions{Signoff: true})
+
+		if err != nil {
+			t.Fatalf("error creating commit: %v", err)
+		}
+
+		message, err := runGit(nil, "log", "-1", "--format=%B")
+		if err != nil {
+			t.Fatalf("error reading commit: %v", err)
+		}
+
+		if !strings.HasPrefix(message, "Test title\n\nTest body\n") {
+			t.Errorf("expected message to start with the template text, got '%s'", message)
+		}
+
+		if !strings.Contains(message, "Signed-off-by: Test <test@example.com>") {
+			t.Errorf("expected message to be signed off, got '%s'", message)
+		}
+	})
+}
+
+func TestStagedPaths(t *testing.T) {
+	initRepo(t)
+
+	names := []string{"services/billing/my file.go", "services/billing/café.go"}
+	for _, name := range names {
+		writeFile(t, name, "")
+	}
+	if _, err := runGit(nil, "add", "."); err != nil {
+		t.Fatalf("error staging files: %v", err)
+	}
+
+	got, err := StagedPaths()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sort.Strings(got)
+	want := []string{"services/billing/café.go", "services/billing/my file.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
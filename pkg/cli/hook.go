@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+const (
+	// hookMarker identifies hooks installed by ct
+	hookMarker = "# Installed by ComTemplate (ct)"
+	// chainedSuffix is appended to the name of a hook replaced by ct
+	chainedSuffix = ".pre-ct"
+	// prepareCommitMsg is the git hook used to run templates
+	prepareCommitMsg = "prepare-commit-msg"
+	// commitMsg is the git hook used to check commit messages
+	commitMsg = "commit-msg"
+)
+
+// HookTypes are the git hooks ct can install
+var HookTypes = []string{prepareCommitMsg, commitMsg}
+
+// generatedPrefixes start the messages git writes itself for merges, reverts
+// and fixups
+var generatedPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// SkipHookSource reports whether the prepare-commit-msg hook should leave the
+// message alone for the given commit message source. Merges, squashes, amends
+// and messages given with -m or -F already have a message.
+func SkipHookSource(source string) bool {
+	return slices.Contains([]string{"merge", "squash", "commit", "message"}, source)
+}
+
+// GeneratedMessage reports whether message was written by git, for a merge,
+// a revert or a fixup, rather than by following a template
+func GeneratedMessage(message string) bool {
+	message = CleanMessage(message)
+	for _, prefix := range generatedPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// hooksDir returns the hooks directory of the current repository
+func hooksDir() (string, error) {
+	output, err := runGit(nil, "rev-parse", "--git-path", "hooks")
+
+	if err != nil {
+		return "", fmt.Errorf("error finding hooks directory: %v", err)
+	}
+
+	return filepath.Abs(strings.TrimSpace(output))
+}
+
+// shellQuote quotes s to be used as a single word in a shell script
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// hookScript returns the script of a hook that chains to the hook it replaced
+// and then runs command. Interactive hooks are skipped when there is no
+// terminal.
+func hookScript(name, command string, interactive bool) string {
+	terminal := ""
+	if interactive {
+		terminal = `
+# Skip the form when there is no terminal to show it on
+( : < /dev/tty ) 2>/dev/null || exit 0
+exec < /dev/tty
+`
+	}
+
+	return fmt.Sprintf(`#!/bin/sh
+%s
+chained="$(dirname "$0")/%s%s"
+if [ -x "$chained" ]; then
+	"$chained" "$@" || exit $?
+fi
+%s
+exec %s "$@"
+`, hookMarker, name, chainedSuffix, terminal, command)
+}
+
+// checkHookType returns an error when ct cannot install hooks of the given type
+func checkHookType(hookType string) error {
+	if !slices.Contains(HookTypes, hookType) {
+		return fmt.Errorf("unknown hook type '%s': expected %s", hookType, strings.Join(HookTypes, " or "))
+	}
+	return nil
+}
+
+// isOwnHook reports whether the hook at path was installed by ct
+func isOwnHook(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), hookMarker)
+}
+
+// installHook writes the hook script, keeping any existing hook so that it is
+// still run before ct
+func installHook(name, script string) (string, error) {
+	dir, err := hooksDir()
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("error creating hooks directory: %v", err)
+	}
+
+	path := filepath.Join(dir, name)
+	chained := path + chainedSuffix
+
+	if _, err := os.Stat(path); err == nil && !isOwnHook(path) {
+		if _, err := os.Stat(chained); err == nil {
+			return "", fmt.Errorf("cannot keep existing hook: %s already exists", chained)
+		}
+
+		err = os.Rename(path, chained)
+		if err != nil {
+			return "", fmt.Errorf("error keeping existing hook: %v", err)
+		}
+	}
+
+	err = os.WriteFile(path, []byte(script), 0755)
+	if err != nil {
+		return "", fmt.Errorf("error writing hook: %v", err)
+	}
+
+	return path, nil
+}
+
+// uninstallHook removes a hook installed by ct and restores the hook it
+// replaced
+func uninstallHook(name string) (string, error) {
+	dir, err := hooksDir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, name)
+	chained := path + chainedSuffix
+
+	if _, err := os.Stat(path); err != nil {
+		return "", fmt.Errorf("no %s hook installed", name)
+	}
+
+	if !isOwnHook(path) {
+		return "", fmt.Errorf("%s hook was not installed by ct", name)
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		return "", fmt.Errorf("error removing hook: %v", err)
+	}
+
+	if _, err := os.Stat(chained); err == nil {
+		err = os.Rename(chained, path)
+		if err != nil {
+			return "", fmt.Errorf("error restoring existing hook: %v", err)
+		}
+	}
+
+	return path, nil
+}
+
+// InstallHook installs a hook that runs the ct executable at bin with the given
+// config and returns the path of the hook. A prepare-commit-msg hook runs
+// template, and a commit-msg hook rejects messages that match no template.
+func InstallHook(hookType, bin, template, config string) (string, error) {
+	if err := checkHookType(hookType); err != nil {
+		return "", err
+	}
+
+	if hookType == commitMsg && template != "" {
+		return "", fmt.Errorf("the %s hook checks every template and takes no template", commitMsg)
+	}
+
+	command := shellQuote(bin)
+	if hookType == commitMsg {
+		command += " check"
+	} else {
+		command += " hook run"
+	}
+	if template != "" {
+		command += " --template " + shellQuote(template)
+	}
+	if config != "" {
+		command += " --config " + shellQuote(config)
+	}
+	if hookType == commitMsg {
+		command += " --file"
+	}
+
+	return installHook(hookType, hookScript(hookType, command, hookType == prepareCommitMsg))
+}
+
+// UninstallHook removes the hook of the given type installed by ct and returns
+// the path of the hook
+func UninstallHook(hookType string) (string, error) {
+	if err := checkHookType(hookType); err != nil {
+		return "", err
+	}
+
+	return uninstallHook(hookType)
+}
+
+// templateSource is the commit message source given to the
+// prepare-commit-msg hook when the file holds the commit.template text
+const templateSource = "template"
+
+// keepComments returns the comment lines of message, starting with
+// commentChar, and everything below the scissors line
+func keepComments(message, commentChar string) string {
+	below := ""
+	if i := strings.Index(message, commentChar+scissors); i >= 0 {
+		message, below = message[:i], message[i:]
+	}
+
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, commentChar) {
+			lines = append(lines, line)
+		}
+	}
+
+	kept := strings.Join(lines, "\n")
+	if kept != "" {
+		kept += "\n"
+	}
+	return kept + below
+}
+
+// PrepareCommitMessage writes text at the top of the commit message file,
+// keeping the help comments written by git below it. With the template
+// source, the commit.template text written by git is replaced by text.
+func PrepareCommitMessage(path, text, source string) error {
+	existing, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("error reading commit message: %v", err)
+	}
+
+	if source == templateSource {
+		existing = []byte(keepComments(string(existing), CommentChar()))
+	}
+
+	message := strings.TrimRight(text, "\n") + "\n"
+	if len(existing) > 0 {
+		message += "\n" + string(existing)
+	}
+
+	err = os.WriteFile(path, []byte(message), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing commit message: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSkipHookSource(t *testing.T) {
+	testCases := []struct {
+		source string
+		want   bool
+	}{
+		{source: "", want: false},
+		{source: "template", want: false},
+		{source: "message", want: true},
+		{source: "merge", want: true},
+		{source: "squash", want: true},
+		{source: "commit", want: true},
+	}
+
+	for _, tc := range testCases {
+		if got := SkipHookSource(tc.source); got != tc.want {
+			t.Errorf("source '%s': expected %v, got %v", tc.source, tc.want, got)
+		}
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	want := `'it'\''s'`
+	if got := shellQuote("it's"); got != want {
+		t.Errorf("expected %s, got %s", want, got)
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	repo := initRepo(t)
+	hookPath := filepath.Join(repo, ".git", "hooks", prepareCommitMsg)
+	chainedPath := hookPath + chainedSuffix
+
+	existing := "#!/bin/sh\necho existing\n"
+	err := os.WriteFile(hookPath, []byte(existing), 0755)
+	if err != nil {
+		t.Fatalf("error writing hook: %v", err)
+	}
+
+	_, err = InstallHook(prepareCommitMsg, "/usr/bin/ct", "feat", "/repo/tools/commit")
+	if err != nil {
+		t.Fatalf("error installing hook: %v", err)
+	}
+
+	t.Run("should write the hook", func(t *testing.T) {
+		data, err := os.ReadFile(hookPath)
+		if err != nil {
+			t.Fatalf("error reading hook: %v", err)
+		}
+
+		if !strings.Contains(string(data), "'/usr/bin/ct' hook run --template 'feat' --config '/repo/tools/commit'") {
+			t.Errorf("expected hook to run ct, got '%s'", data)
+		}
+	})
+
+	t.Run("should keep the existing hook", func(t *testing.T) {
+		data, err := os.ReadFile(chainedPath)
+		if err != nil {
+			t.Fatalf("error reading chained hook: %v", err)
+		}
+
+		if string(data) != existing {
+			t.Errorf("expected chained hook to be '%s', got '%s'", existing, data)
+		}
+	})
+
+	t.Run("should reinstall over its own hook", func(t *testing.T) {
+		_, err := InstallHook(prepareCommitMsg, "/usr/bin/ct", "", "")
+		if err != nil {
+			t.Errorf("error reinstalling hook: %v", err)
+		}
+	})
+
+	t.Run("should restore the existing hook on uninstall", func(t *testing.T) {
+		_, err := UninstallHook(prepareCommitMsg)
+		if err != nil {
+			t.Fatalf("error uninstalling hook: %v", err)
+		}
+
+		data, err := os.ReadFile(hookPath)
+		if err != nil {
+			t.Fatalf("error reading hook: %v", err)
+		}
+
+		if string(data) != existing {
+			t.Errorf("expected hook to be '%s', got '%s'", existing, data)
+		}
+
+		if _, err := os.Stat(chainedPath); err == nil {
+			t.Errorf("expected chained hook to be removed")
+		}
+	})
+
+	t.Run("should refuse to uninstall a foreign hook", func(t *testing.T) {
+		if _, err := UninstallHook(prepareCommitMsg); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+func TestInstallCommitMsgHook(t *testing.T) {
+	repo := initRepo(t)
+	hookPath := filepath.Join(repo, ".git", "hooks", commitMsg)
+
+	t.Run("should reject a template", func(t *testing.T) {
+		if _, err := InstallHook(commitMsg, "/usr/bin/ct", "feat", ""); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should reject unknown hook types", func(t *testing.T) {
+		if _, err := InstallHook("pre-push", "/usr/bin/ct", "", ""); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should write the hook", func(t *testing.T) {
+		_, err := InstallHook(commitMsg, "/usr/bin/ct", "", "/repo/comtemplate.yml")
+		if err != nil {
+			t.Fatalf("error installing hook: %v", err)
+		}
+
+		data, err := os.ReadFile(hookPath)
+		if err != nil {
+			t.Fatalf("error reading hook: %v", err)
+		}
+
+		if !strings.Contains(string(data), `'/usr/bin/ct' check --config '/repo/comtemplate.yml' --file "$@"`) {
+			t.Errorf("expected hook to run ct check, got '%s'", data)
+		}
+
+		if strings.Contains(string(data), "/dev/tty") {
+			t.Errorf("expected hook to run without a terminal, got '%s'", data)
+		}
+	})
+}
+
+func TestGeneratedMessage(t *testing.T) {
+	testCases := []struct {
+		message string
+		want    bool
+	}{
+		{message: "Merge branch 'main' into feature\n", want: true},
+		{message: "Revert \"feat: add check\"\n\nThis reverts commit abc.\n", want: true},
+		{message: "fixup! feat: add check\n", want: true},
+		{message: "feat: merge configs\n", want: false},
+	}
+
+	for _, tc := range testCases {
+		if got := GeneratedMessage(tc.message); got != tc.want {
+			t.Errorf("message %q: expected %v, got %v", tc.message, tc.want, got)
+		}
+	}
+}
+
+func TestPrepareCommitMessage(t *testing.T) {
+	f, err := os.CreateTemp("", "COMMIT_EDITMSG")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.WriteString("\n# Please enter the commit message\n")
+	if err != nil {
+		t.Fatalf("error writing to temp file: %v", err)
+	}
+	f.Close()
+
+	err = PrepareCommitMessage(f.Name(), "Test title\n", "")
+	if err != nil {
+		t.Fatalf("error preparing commit message: %v", err)
+	}
+
+	data, err := os.ReadFile(f.Name())
+	if err != nil {
+		t.Fatalf("error reading file: %v", err)
+	}
+
+	want := "Test title\n\n\n# Please enter the commit message\n"
+	if string(data) != want {
+		t.Errorf("expected message to be '%s', got '%s'", want, data)
+	}
+}
+
+func TestPrepareCommitMessageReplacesTemplate(t *testing.T) {
+	initRepo(t)
+
+	path := filepath.Join(t.TempDir(), "COMMIT_EDITMSG")
+	existing := "Subject line\n\nWhy:\n# Please enter the commit message\n#" + scissors + "\ndiff --git a/x b/x\n"
+	writeFile(t, path, existing)
+
+	if err := PrepareCommitMessage(path, "Test title\n", templateSource); err != nil {
+		t.Fatalf("error preparing commit message: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error reading file: %v", err)
+	}
+
+	want := "Test title\n\n# Please enter the commit message\n#" + scissors + "\ndiff --git a/x b/x\n"
+	if string(data) != want {
+		t.Errorf("expected message to be %q, got %q", want, data)
+	}
+}
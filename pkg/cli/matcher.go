@@ -0,0 +1,340 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// scissors follows the comment character on the line below which git ignores
+// the commit message
+const scissors = " ------------------------ >8 ------------------------"
+
+// defaultCommentChar starts comment lines unless core.commentChar is set
+const defaultCommentChar = "#"
+
+// segment is a part of the regular expression matching a template
+type segment struct {
+	pattern string
+	// expected describes what the segment matches
+	expected string
+}
+
+// matcher matches commit messages against a template
+type matcher struct {
+	template Template
+	segments []segment
+	re       *regexp.Regexp
+}
+
+// literalPattern returns the pattern matching literal template text. Since
+// rendered messages have their blank lines normalized and git strips trailing
+// spaces, whitespace only has to match loosely.
+func literalPattern(text string) string {
+	var builder strings.Builder
+
+	for len(text) > 0 {
+		end := strings.IndexFunc(text, func(r rune) bool { return unicode.IsSpace(r) != unicode.IsSpace(rune(text[0])) })
+		if end < 0 {
+			end = len(text)
+		}
+
+		part := text[:end]
+		text = text[end:]
+
+		switch {
+		case strings.TrimSpace(part) != "":
+			builder.WriteString(regexp.QuoteMeta(part))
+		case strings.Contains(part, "\n"):
+			builder.WriteString(`\s*`)
+		default:
+			builder.WriteString(`[ \t]*`)
+		}
+	}
+
+	return builder.String()
+}
+
+// variablePattern returns the pattern matching the value of a variable
+func variablePattern(variable Variable, found bool, filtered bool) string {
+	switch {
+	case !found || filtered:
+		return `(?s:.*?)`
+	case variable.computed() && variable.From != "":
+		values := variable.mapValues()
+		if variable.Default != "" && !slices.Contains(values, variable.Default) {
+			values = append(values, variable.Default)
+		}
+		for i, value := range values {
+			values[i] = regexp.QuoteMeta(value)
+		}
+		return "(?:" + strings.Join(values, "|") + ")?"
+	case variable.computed():
+		return `(?s:.*?)`
+	case variable.dynamicOptions() && variable.Type == "multiselect":
+		// Options loaded at runtime may have changed since the commit
+		return `[^\n]*?`
+	case variable.Type == "select" && len(variable.allOptions()) > 0 && !variable.dynamicOptions():
+		options := make([]string, len(variable.allOptions()))
+		for i, option := range variable.allOptions() {
+			options[i] = regexp.QuoteMeta(option)
+		}
+		return "(?:" + strings.Join(options, "|") + ")"
+	case variable.Type == "confirm":
+		pattern := "(?:" + regexp.QuoteMeta(variable.trueValue())
+		if variable.FalseValue == "" {
+			return pattern + ")?"
+		}
+		return pattern + "|" + regexp.QuoteMeta(variable.FalseValue) + ")"
+	case variable.Type == "multiselect" && len(variable.allOptions()) > 0:
+		options := make([]string, len(variable.allOptions()))
+		for i, option := range variable.allOptions() {
+			options[i] = regexp.QuoteMeta(option)
+		}
+		option := "(?:" + strings.Join(options, "|") + ")"
+		pattern := "(?:" + option + "(?:" + regexp.QuoteMeta(variable.separator()) + option + ")*)"
+		if variable.minSelections() == 0 {
+			return pattern + "?"
+		}
+		return pattern
+	case variable.Type == "number" && variable.Required:
+		return `-?[0-9]+`
+	case variable.Type == "number":
+		return `(?:-?[0-9]+)?`
+	case variable.Pattern != "":
+		pattern := strings.TrimSuffix(strings.TrimPrefix(variable.Pattern, "^"), "$")
+		if variable.Required {
+			return "(?:" + pattern + ")"
+		}
+		return "(?:" + pattern + ")?"
+	case variable.Type == "text" && variable.Required:
+		return `(?s:.+?)`
+	case variable.Type == "text":
+		return `(?s:.*?)`
+	case variable.Required:
+		return `[^\n]+?`
+	default:
+		return `[^\n]*?`
+	}
+}
+
+// describe returns a description of a variable for mismatch reports
+func describe(variable Variable, found bool) string {
+	if found && variable.dynamicOptions() {
+		return variable.Name
+	}
+	if found && variable.Type == "select" && len(variable.allOptions()) > 0 {
+		return fmt.Sprintf("%s (one of: %s)", variable.Name, strings.Join(variable.allOptions(), ", "))
+	}
+	if found && variable.Type == "multiselect" && len(variable.allOptions()) > 0 {
+		return fmt.Sprintf("%s (any of: %s)", variable.Name, strings.Join(variable.allOptions(), ", "))
+	}
+	if found && variable.Type == "number" {
+		return fmt.Sprintf("%s (a number)", variable.Name)
+	}
+	if found && variable.Pattern != "" {
+		return fmt.Sprintf("%s matching %s", variable.Name, variable.Pattern)
+	}
+	return variable.Name
+}
+
+// segments returns the segments matching nodes
+func segments(nodes []node, variables map[string]Variable) []segment {
+	var result []segment
+
+	for _, n := range nodes {
+		switch n.kind {
+		case textNode:
+			pattern := literalPattern(n.text)
+			expected := strings.TrimSpace(n.text)
+			if expected == "" {
+				expected = "line break"
+			} else {
+				expected = fmt.Sprintf("%q", expected)
+			}
+			result = append(result, segment{pattern: pattern, expected: expected})
+		case varNode:
+			variable, found := variables[n.name]
+			if !found {
+				variable.Name = n.name
+			}
+			result = append(result, segment{
+				pattern:  variablePattern(variable, found, len(n.filters) > 0),
+				expected: describe(variable, found),
+			})
+		case blockNode:
+			var pattern strings.Builder
+			for _, child := range segments(n.children, variables) {
+				pattern.WriteString(child.pattern)
+			}
+			result = append(result, segment{
+				pattern:  "(?:" + pattern.String() + ")?",
+				expected: fmt.Sprintf("optional %s section", n.name),
+			})
+		}
+	}
+
+	return result
+}
+
+// newMatcher compiles the text of template into a matcher
+func newMatcher(template Template) (*matcher, error) {
+	nodes, err := parseText(template.Text)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %v", template.Name, err)
+	}
+
+	variables := make(map[string]Variable)
+	for _, variable := range template.Variables {
+		variables[variable.Name] = variable
+	}
+
+	m := &matcher{template: template, segments: segments(nodes, variables)}
+
+	m.re, err = m.compile(len(m.segments), true)
+	if err != nil {
+		return nil, fmt.Errorf("template %s: %v", template.Name, err)
+	}
+
+	return m, nil
+}
+
+// compile compiles the first n segments, anchored at the end of the message
+// when whole is true
+func (m *matcher) compile(n int, whole bool) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString(`^\s*`)
+
+	for _, s := range m.segments[:n] {
+		builder.WriteString(s.pattern)
+	}
+
+	if whole {
+		builder.WriteString(`\s*$`)
+	}
+
+	return regexp.Compile(builder.String())
+}
+
+// mismatch returns how many segments match the start of message and where the
+// next segment should have matched
+func (m *matcher) mismatch(message string) (int, int) {
+	matched, end := 0, 0
+
+	for n := 1; n <= len(m.segments); n++ {
+		re, err := m.compile(n, false)
+		if err != nil {
+			break
+		}
+
+		loc := re.FindStringIndex(message)
+		if loc == nil {
+			break
+		}
+
+		matched, end = n, loc[1]
+	}
+
+	return matched, end
+}
+
+// Match is the result of matching a commit message against templates
+type Match struct {
+	// Template is the matching template, or the closest one when none
+	// matches
+	Template Template
+	// OK is true when the message matches Template
+	OK bool
+	// Expected describes the part of Template that does not match, and
+	// Line is the line of the message where it was expected
+	Expected string
+	Line     int
+}
+
+// StripComments removes what git strips from a message file before
+// committing it: lines starting with commentChar and everything below the
+// scissors line. Messages of existing commits must not be stripped, since git
+// already kept what belongs to them.
+func StripComments(message, commentChar string) string {
+	if i := strings.Index(message, commentChar+scissors); i >= 0 {
+		message = message[:i]
+	}
+
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if !strings.HasPrefix(line, commentChar) {
+			lines = append(lines, line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// CleanMessage removes the trailing spaces and extra blank lines of a commit
+// message
+func CleanMessage(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		lines = append(lines, strings.TrimRightFunc(line, unicode.IsSpace))
+	}
+
+	return strings.TrimSuffix(normalizeBlankLines(strings.Join(lines, "\n")), "\n")
+}
+
+// MatchMessage matches a commit message against templates. Comments must
+// already be removed from messages read from files, with StripComments. When
+// no template matches, the returned match describes the closest template.
+func MatchMessage(templates []Template, message string) (Match, error) {
+	if len(templates) == 0 {
+		return Match{}, fmt.Errorf("no templates to match against")
+	}
+
+	message = CleanMessage(message)
+
+	var closest Match
+	bestScore := -1.0
+
+	for _, template := range templates {
+		m, err := newMatcher(template)
+		if err != nil {
+			return Match{}, err
+		}
+
+		if m.re.MatchString(message) {
+			return Match{Template: template, OK: true}, nil
+		}
+
+		matched, end := m.mismatch(message)
+
+		score := 1.0
+		if len(m.segments) > 0 {
+			score = float64(matched) / float64(len(m.segments))
+		}
+
+		if score <= bestScore {
+			continue
+		}
+		bestScore = score
+
+		closest = Match{
+			Template: template,
+			Expected: "end of message",
+			Line:     strings.Count(message[:end], "\n") + 1,
+		}
+		if matched < len(m.segments) {
+			closest.Expected = m.segments[matched].expected
+		}
+	}
+
+	return closest, nil
+}
+
+// String describes the result of the match
+func (m Match) String() string {
+	if m.OK {
+		return fmt.Sprintf("matches template '%s'", m.Template.Name)
+	}
+	return fmt.Sprintf("does not match any template; closest is '%s', expected %s at line %d", m.Template.Name, m.Expected, m.Line)
+}
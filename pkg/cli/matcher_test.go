@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"testing"
+)
+
+var matchTemplates = []Template{
+	{
+		Name: "conventional",
+		Text: "%{type}%{?scope}(%{scope})%{/scope}: %{title}\n\n%{?body}%{body}%{/body}\n",
+		Variables: []Variable{
+			{Name: "type", Type: "select", Options: []string{"feat", "fix"}},
+			{Name: "scope"},
+			{Name: "title", Required: true},
+			{Name: "body", Type: "text"},
+		},
+	},
+	{
+		Name: "jira",
+		Text: "[%{ticket}] %{title}\n",
+		Variables: []Variable{
+			{Name: "ticket", Pattern: "^[A-Z]+-[0-9]+$", Required: true},
+			{Name: "title", Required: true},
+		},
+	},
+}
+
+func TestMatchMessage(t *testing.T) {
+	testCases := []struct {
+		message  string
+		ok       bool
+		template string
+		expected string
+		line     int
+	}{
+		{message: "feat: add check command\n", ok: true, template: "conventional"},
+		{message: "fix(cli): handle empty values", ok: true, template: "conventional"},
+		{message: "feat: title\n\nA body\nover lines\n", ok: true, template: "conventional"},
+		{message: "feat: title\n# Please enter the commit message\n", ok: true, template: "conventional"},
+		{message: "[ABC-12] Fix login\n", ok: true, template: "jira"},
+		{message: "chore: bump deps\n", template: "conventional", expected: "type (one of: feat, fix)", line: 1},
+		{message: "[abc] Fix login\n", template: "jira", expected: "ticket matching ^[A-Z]+-[0-9]+$", line: 1},
+		{message: "feat:\n", template: "conventional", expected: "title", line: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			match, err := MatchMessage(matchTemplates, tc.message)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if match.OK != tc.ok {
+				t.Fatalf("expected OK to be %v, got %v (%s)", tc.ok, match.OK, match)
+			}
+
+			if match.Template.Name != tc.template {
+				t.Errorf("expected template %s, got %s", tc.template, match.Template.Name)
+			}
+
+			if match.Expected != tc.expected {
+				t.Errorf("expected %q, got %q", tc.expected, match.Expected)
+			}
+
+			if match.Line != tc.line {
+				t.Errorf("expected line %d, got %d", tc.line, match.Line)
+			}
+		})
+	}
+}
+
+func TestCleanMessage(t *testing.T) {
+	message := "Title  \n\n\n\nBody\n# comment\n#" + scissors + "\ndiff --git a/x b/x\n"
+	want := "Title\n\nBody"
+
+	if got := CleanMessage(StripComments(message, "#")); got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	message = "#12 fix stuff\n; comment\n"
+	if got := CleanMessage(StripComments(message, ";")); got != "#12 fix stuff" {
+		t.Errorf("expected lines without the comment char to be kept, got %q", got)
+	}
+
+	if got := CleanMessage(message); got != "#12 fix stuff\n; comment" {
+		t.Errorf("expected comments to be kept without StripComments, got %q", got)
+	}
+}
+
+func TestCommentChar(t *testing.T) {
+	initRepo(t)
+
+	if got := CommentChar(); got != "#" {
+		t.Errorf("expected default comment char, got %q", got)
+	}
+
+	if _, err := runGit(nil, "config", "core.commentChar", ";"); err != nil {
+		t.Fatalf("error setting comment char: %v", err)
+	}
+	if got := CommentChar(); got != ";" {
+		t.Errorf("expected ; from core.commentChar, got %q", got)
+	}
+}
+
+func TestMatchMessageKeepsHashLines(t *testing.T) {
+	template := Template{Name: "issue", Text: "#%{issue} %{summary}", Variables: []Variable{{Name: "issue", Type: "number"}, {Name: "summary"}}}
+
+	match, err := MatchMessage([]Template{template}, "#12 fix stuff\n")
+	if err != nil {
+		t.Fatalf("error matching message: %v", err)
+	}
+	if !match.OK {
+		t.Errorf("expected message starting with # to match, got %s", match)
+	}
+}
+
+func TestCommitMessages(t *testing.T) {
+	initRepo(t)
+
+	for _, message := range []string{"feat: one\n", "fix: two\n\nBody\n"} {
+		if _, err := runGit(nil, "commit", "--allow-empty", "--quiet", "-m", message); err != nil {
+			t.Fatalf("error creating commit: %v", err)
+		}
+	}
+
+	commits, err := CommitMessages("HEAD~1..HEAD")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit, got %d", len(commits))
+	}
+
+	if got := CleanMessage(commits[0].Message); got != "fix: two\n\nBody" {
+		t.Errorf("expected message of the last commit, got %q", got)
+	}
+}
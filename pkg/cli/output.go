@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Output kinds a populated template can be sent to
+const (
+	OutputClipboard = "clipboard"
+	OutputStdout    = "stdout"
+	OutputFile      = "file"
+	OutputGitMsg    = "git-msg"
+)
+
+// Output is where a populated template is sent
+type Output struct {
+	Kind string
+	Path string
+	// Clipboard is the backend used by the clipboard output. When nil, one is
+	// picked from the environment.
+	Clipboard Clipboard
+}
+
+// ParseOutput parses an output given as 'clipboard', 'stdout', 'file:<path>'
+// or 'git-msg'
+func ParseOutput(s string) (Output, error) {
+	kind, path, _ := strings.Cut(s, ":")
+
+	switch kind {
+	case OutputClipboard, OutputStdout, OutputGitMsg:
+		if path != "" {
+			return Output{}, fmt.Errorf("output %s does not take a path", kind)
+		}
+	case OutputFile:
+		if path == "" {
+			return Output{}, fmt.Errorf("output file requires a path: file:<path>")
+		}
+	default:
+		return Output{}, fmt.Errorf("unknown output '%s': expected clipboard, stdout, file:<path> or git-msg", s)
+	}
+
+	return Output{Kind: kind, Path: path}, nil
+}
+
+// IsTerminal reports whether f is a terminal
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// gitMessagePath returns the path of COMMIT_EDITMSG in the current repository
+func gitMessagePath() (string, error) {
+	path, err := runGit(nil, "rev-parse", "--git-path", "COMMIT_EDITMSG")
+
+	if err != nil {
+		return "", fmt.Errorf("error finding COMMIT_EDITMSG: %v", err)
+	}
+
+	return strings.TrimSpace(path), nil
+}
+
+// writeMessage writes text to the file at path
+func writeMessage(path, text string) error {
+	err := os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return fmt.Errorf("error creating directory: %v", err)
+	}
+
+	err = os.WriteFile(path, []byte(text), 0644)
+	if err != nil {
+		return fmt.Errorf("error writing file: %v", err)
+	}
+
+	return nil
+}
+
+// Send sends text to the output and returns a short confirmation message
+func (o Output) Send(text string) (string, error) {
+	switch o.Kind {
+	case OutputStdout:
+		_, err := fmt.Fprint(os.Stdout, text)
+		if err != nil {
+			return "", fmt.Errorf("error writing to stdout: %v", err)
+		}
+		return "", nil
+	case OutputFile:
+		err := writeMessage(o.Path, text)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Written to %s", o.Path), nil
+	case OutputGitMsg:
+		path, err := gitMessagePath()
+		if err != nil {
+			return "", err
+		}
+		err = writeMessage(path, text)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("Written to %s", path), nil
+	default:
+		c := o.Clipboard
+		if c == nil {
+			var err error
+			c, err = NewClipboard(ClipboardAuto)
+			if err != nil {
+				return "", err
+			}
+		}
+		err := c.Copy(text)
+		if err != nil {
+			return "", fmt.Errorf("error copying to %s: %v", c, err)
+		}
+		return fmt.Sprintf("Copied to %s", c), nil
+	}
+}
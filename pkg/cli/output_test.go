@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseOutput(t *testing.T) {
+	testCases := []struct {
+		s       string
+		want    Output
+		wantErr bool
+	}{
+		{s: "clipboard", want: Output{Kind: OutputClipboard}},
+		{s: "stdout", want: Output{Kind: OutputStdout}},
+		{s: "git-msg", want: Output{Kind: OutputGitMsg}},
+		{s: "file:msg.txt", want: Output{Kind: OutputFile, Path: "msg.txt"}},
+		{s: "file:C:/msg.txt", want: Output{Kind: OutputFile, Path: "C:/msg.txt"}},
+		{s: "file", wantErr: true},
+		{s: "stdout:path", wantErr: true},
+		{s: "printer", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.s, func(t *testing.T) {
+			got, err := ParseOutput(tc.s)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error parsing output: %v", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected output %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSendOutput(t *testing.T) {
+	repo := initRepo(t)
+
+	t.Run("should write to a file", func(t *testing.T) {
+		path := filepath.Join(repo, "messages", "msg.txt")
+
+		_, err := Output{Kind: OutputFile, Path: path}.Send("Test title\n")
+		if err != nil {
+			t.Fatalf("error sending output: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("error reading file: %v", err)
+		}
+
+		if string(data) != "Test title\n" {
+			t.Errorf("expected file to contain 'Test title\n', got '%s'", data)
+		}
+	})
+
+	t.Run("should write to COMMIT_EDITMSG", func(t *testing.T) {
+		_, err := Output{Kind: OutputGitMsg}.Send("Test title\n")
+		if err != nil {
+			t.Fatalf("error sending output: %v", err)
+		}
+
+		data, err := os.ReadFile(filepath.Join(repo, ".git", "COMMIT_EDITMSG"))
+		if err != nil {
+			t.Fatalf("error reading file: %v", err)
+		}
+
+		if string(data) != "Test title\n" {
+			t.Errorf("expected file to contain 'Test title\n', got '%s'", data)
+		}
+	})
+}
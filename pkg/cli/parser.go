@@ -3,8 +3,12 @@ package cli
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"slices"
 	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/huh"
 	"gopkg.in/yaml.v3"
@@ -16,35 +20,120 @@ type Template struct {
 	Description string     `yaml:"description"`
 	Text        string     `yaml:"text"`
 	Variables   []Variable `yaml:"variables"`
+	// Pages split the form into pages. Variables left out of every page are
+	// asked for on a last page.
+	Pages []Page `yaml:"pages"`
+	// Source is the file the template was read from
+	Source string `yaml:"-"`
+
+	// line is the YAML line of the template and textLine the YAML line
+	// where its text starts, or zero when unknown
+	line     int
+	textLine int
 }
 
 func (t Template) validate(id int) error {
-	var errBuilder strings.Builder
+	var errs ValidationErrors
 
 	if t.Name == "" {
-		fmt.Fprintf(&errBuilder, "Template %d: name is empty\n", id)
+		errs.add(id, -1, "name", t.line, "name is empty")
 	}
 
 	if t.Text == "" {
-		fmt.Fprintf(&errBuilder, "Template %d: text is empty\n", id)
+		errs.add(id, -1, "text", t.line, "text is empty")
+	}
+
+	// textLineOf returns the YAML line of a line of the text
+	textLineOf := func(line int) int {
+		if t.textLine == 0 {
+			return t.line
+		}
+		return t.textLine + line - 1
+	}
+
+	nodes, err := parseText(t.Text)
+	if parseErr, ok := err.(ParseError); ok {
+		errs.add(id, -1, "text", textLineOf(parseErr.Line), "%s at column %d", parseErr.Message, parseErr.Col)
+	} else if err != nil {
+		errs.add(id, -1, "text", t.line, "%v", err)
+	}
+	used := referencedNames(nodes)
+
+	declared := make(map[string]bool)
+	for _, variable := range t.Variables {
+		declared[variable.Name] = true
 	}
 
 	for varId, variable := range t.Variables {
-		if !strings.Contains(t.Text, fmt.Sprintf("%%{%s}", variable.Name)) {
-			fmt.Fprintf(&errBuilder, "Template %d - Variable %d: variable %s is not used in text\n", id, varId, variable.Name)
+		if !variable.computed() {
+			continue
+		}
+		for _, name := range variable.dependencies() {
+			if !declared[name] {
+				errs.add(id, varId, "value", variable.line, "computed variable %s depends on %s which is not declared", variable.Name, name)
+			}
+			used = append(used, name)
+		}
+	}
+
+	if _, orderErr := computeOrder(t.Variables); orderErr != nil {
+		errs.add(id, -1, "variables", t.line, "%v", orderErr)
+	}
+
+	for _, n := range placeholders(nodes) {
+		if !declared[n.name] {
+			errs.add(id, -1, "text", textLineOf(n.line), "placeholder %s has no matching variable", n.text)
+		}
+	}
+
+	seen := make(map[string]bool)
+	for varId, variable := range t.Variables {
+		if variable.Name != "" && seen[variable.Name] {
+			errs.add(id, varId, "name", variable.line, "variable %s is declared more than once", variable.Name)
+		}
+		seen[variable.Name] = true
+
+		if err == nil && !slices.Contains(used, variable.Name) {
+			errs.add(id, varId, "name", variable.line, "variable %s is not used in text", variable.Name)
 		}
 
 		if validationErr := variable.validate(id, varId); validationErr != nil {
-			fmt.Fprintf(&errBuilder, "%v\n", validationErr)
+			errs = append(errs, validationErr.(ValidationErrors)...)
 		}
 	}
 
-	if errBuilder.Len() > 0 {
-		return fmt.Errorf(errBuilder.String())
+	onPage := make(map[string]bool)
+	for pageId, page := range t.Pages {
+		if len(page.Variables) == 0 {
+			errs.add(id, -1, "pages", page.line, "page %d has no variables", pageId)
+		}
+
+		for _, name := range page.Variables {
+			if !declared[name] {
+				errs.add(id, -1, "pages", page.line, "page %d lists variable %s which is not declared", pageId, name)
+			}
+			if i := slices.IndexFunc(t.Variables, func(v Variable) bool { return v.Name == name }); i >= 0 && t.Variables[i].computed() {
+				errs.add(id, -1, "pages", page.line, "page %d lists computed variable %s, which is not asked for", pageId, name)
+			}
+			if onPage[name] {
+				errs.add(id, -1, "pages", page.line, "variable %s is on more than one page", name)
+			}
+			onPage[name] = true
+		}
 	}
 
-	return nil
+	return errs.orNil()
+}
+
+// Page is a page of the form of a template
+type Page struct {
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	// Variables are the names of the variables asked for on the page
+	Variables []string `yaml:"variables"`
 
+	// line is the YAML line of the page, or zero when unknown
+	line int
 }
 
 // Varialbe is a variable in a git commit template
@@ -52,49 +141,300 @@ type Variable struct {
 	Name    string   `yaml:"name"`
 	Type    string   `yaml:"type"`
 	Options []string `yaml:"options"`
+	Default string   `yaml:"default"`
+
+	// Title, Description and Placeholder are shown in the form. The title
+	// defaults to the name.
+	Title       string `yaml:"title"`
+	Description string `yaml:"description"`
+	Placeholder string `yaml:"placeholder"`
+
+	Required  bool   `yaml:"required"`
+	MinLength int    `yaml:"min_length"`
+	MaxLength int    `yaml:"max_length"`
+	Pattern   string `yaml:"pattern"`
+
+	// TrueValue and FalseValue are rendered for the answers of confirm
+	// variables. They default to "yes" and an empty value, so that blocks of
+	// the variable are dropped when the answer is no.
+	TrueValue  string `yaml:"true_value"`
+	FalseValue string `yaml:"false_value"`
+	// Separator joins the options chosen in multiselect variables
+	Separator     string `yaml:"separator"`
+	MinSelections int    `yaml:"min_selections"`
+	MaxSelections int    `yaml:"max_selections"`
+	// Min and Max limit the values of number variables
+	Min *int `yaml:"min"`
+	Max *int `yaml:"max"`
+	// Format is the Go time layout of date variables, 2006-01-02 by default
+	Format string `yaml:"format"`
+
+	// Source is where the value is taken from before the form is shown. With
+	// "branch", it is the first capture group of Regex in the current git
+	// branch, or the whole branch without Regex. Prefill shows the value in
+	// the form instead of using it directly.
+	Source  string `yaml:"source"`
+	Regex   string `yaml:"regex"`
+	Prefill bool   `yaml:"prefill"`
+
+	// OptionsFrom adds options computed before the form is shown. With
+	// "staged_paths", the scopes of Paths, rules written as "glob -> scope",
+	// are added and the scopes of the staged files are suggested first.
+	OptionsFrom string   `yaml:"options_from"`
+	Paths       []string `yaml:"paths"`
+
+	// OptionsCommand and OptionsFile add options listed one per line. The
+	// command only runs when the allow_commands setting is on, with sh in the
+	// current directory. It is stopped after OptionsTimeout and its output is
+	// reused for OptionsCache. The file is relative to the template file.
+	OptionsCommand string `yaml:"options_command"`
+	OptionsFile    string `yaml:"options_file"`
+	OptionsTimeout string `yaml:"options_timeout"`
+	OptionsCache   string `yaml:"options_cache"`
+
+	// Value and From compute the value of computed variables, which are not
+	// asked for. Value is written like the template text, such as
+	// "Refs: %{ticket}". From names a variable whose value is looked up in
+	// Map, falling back to Default.
+	Value string            `yaml:"value"`
+	From  string            `yaml:"from"`
+	Map   map[string]string `yaml:"map"`
+
+	// line is the YAML line of the variable, or zero when unknown
+	line int
+}
+
+// reservedNameChars are characters of the template syntax that cannot be used
+// in variable names
+const reservedNameChars = "{}|%"
+
+// reservedName returns why name is reserved by the template syntax, or an
+// empty string when name can be used
+func reservedName(name string) string {
+	switch {
+	case strings.HasPrefix(name, "?"), strings.HasPrefix(name, "/"):
+		return "names starting with ? or / are reserved for blocks"
+	case strings.ContainsAny(name, reservedNameChars):
+		return "the characters " + reservedNameChars + " are reserved by the template syntax"
+	case strings.IndexFunc(name, unicode.IsSpace) >= 0:
+		return "names cannot contain spaces"
+	}
+	return ""
 }
 
 func (v Variable) validate(TemplateId, VarId int) error {
-	inputTypes := []string{"", "input", "text", "select"}
-	var errBuilder strings.Builder
+	inputTypes := []string{"", "input", "text", "select", "confirm", "multiselect", "number", "date", "computed"}
+	var errs ValidationErrors
 
 	if v.Name == "" {
-		fmt.Fprintf(&errBuilder, "Template %d - Variable %d: variable name is empty\n", TemplateId, VarId)
+		errs.add(TemplateId, VarId, "name", v.line, "variable name is empty")
+	}
+
+	if reason := reservedName(v.Name); reason != "" {
+		errs.add(TemplateId, VarId, "name", v.line, "variable name %s is reserved: %s", v.Name, reason)
 	}
 
 	if !slices.Contains(inputTypes, v.Type) {
-		fmt.Fprintf(&errBuilder, "Template %d - Variable %d: variable %s has invalid type %s\n", TemplateId, VarId, v.Name, v.Type)
+		errs.add(TemplateId, VarId, "type", v.line, "variable %s has invalid type %s", v.Name, v.Type)
 	}
 
-	if errBuilder.Len() > 0 {
-		return fmt.Errorf(errBuilder.String())
+	if v.Type == "select" && !v.dynamicOptions() && v.Default != "" && !slices.Contains(v.allOptions(), v.Default) {
+		errs.add(TemplateId, VarId, "default", v.line, "variable %s has default %s which is not one of its options", v.Name, v.Default)
+	}
+
+	if v.MinLength < 0 || v.MaxLength < 0 {
+		errs.add(TemplateId, VarId, "min_length", v.line, "variable %s has a negative length limit", v.Name)
+	}
+
+	if v.MaxLength > 0 && v.MinLength > v.MaxLength {
+		errs.add(TemplateId, VarId, "min_length", v.line, "variable %s has min_length greater than max_length", v.Name)
+	}
+
+	if _, err := regexp.Compile(v.Pattern); err != nil {
+		errs.add(TemplateId, VarId, "pattern", v.line, "variable %s has invalid pattern: %v", v.Name, err)
+	}
+
+	v.validateType(TemplateId, VarId, &errs)
+	v.validateComputed(TemplateId, VarId, &errs)
+
+	if v.Source != "" && v.Source != sourceBranch {
+		errs.add(TemplateId, VarId, "source", v.line, "variable %s has invalid source %s", v.Name, v.Source)
+	}
+
+	if _, err := regexp.Compile(v.Regex); err != nil {
+		errs.add(TemplateId, VarId, "regex", v.line, "variable %s has invalid regex: %v", v.Name, err)
+	}
+
+	if v.Regex != "" && v.Source == "" {
+		errs.add(TemplateId, VarId, "regex", v.line, "variable %s has a regex but no source", v.Name)
+	}
+
+	switch {
+	case v.OptionsFrom == "" && len(v.Paths) > 0:
+		errs.add(TemplateId, VarId, "paths", v.line, "variable %s has paths but no options_from", v.Name)
+	case v.OptionsFrom == "":
+	case v.OptionsFrom != sourceStagedPaths:
+		errs.add(TemplateId, VarId, "options_from", v.line, "variable %s has invalid options_from %s", v.Name, v.OptionsFrom)
+	case v.Type != "select" && v.Type != "multiselect":
+		errs.add(TemplateId, VarId, "options_from", v.line, "variable %s has options_from but is not a select or multiselect", v.Name)
+	case len(v.Paths) == 0:
+		errs.add(TemplateId, VarId, "paths", v.line, "variable %s has options_from %s but no paths", v.Name, v.OptionsFrom)
+	}
+
+	for _, rule := range v.Paths {
+		if _, err := parsePathRule(rule); err != nil {
+			errs.add(TemplateId, VarId, "paths", v.line, "variable %s: %v", v.Name, err)
+		}
+	}
+
+	if v.dynamicOptions() && v.Type != "select" && v.Type != "multiselect" {
+		errs.add(TemplateId, VarId, "options_command", v.line, "variable %s has dynamic options but is not a select or multiselect", v.Name)
+	}
+
+	for _, setting := range [][2]string{{"options_timeout", v.OptionsTimeout}, {"options_cache", v.OptionsCache}} {
+		field, value := setting[0], setting[1]
+		if value == "" {
+			continue
+		}
+		if v.OptionsCommand == "" {
+			errs.add(TemplateId, VarId, field, v.line, "variable %s has %s but no options_command", v.Name, field)
+		}
+		if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+			errs.add(TemplateId, VarId, field, v.line, "variable %s has invalid %s %s: expected a duration such as 10s", v.Name, field, value)
+		}
+	}
+
+	return errs.orNil()
+}
+
+// check returns an error when value does not satisfy the constraints of the
+// variable. Empty values of optional variables are always accepted, and so
+// are the answers of confirm variables.
+func (v Variable) check(value string) error {
+	if value == "" {
+		if v.Required && v.Type != "confirm" {
+			return fmt.Errorf("%s is required", v.Name)
+		}
+		return nil
+	}
+
+	if err := v.checkType(value); err != nil {
+		return err
+	}
+
+	length := utf8.RuneCountInString(value)
+
+	if v.MinLength > 0 && length < v.MinLength {
+		return fmt.Errorf("%s must be at least %d characters long", v.Name, v.MinLength)
+	}
+
+	if v.MaxLength > 0 && length > v.MaxLength {
+		return fmt.Errorf("%s must be at most %d characters long", v.Name, v.MaxLength)
+	}
+
+	if v.Pattern != "" {
+		re, err := regexp.Compile(v.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s has invalid pattern: %v", v.Name, err)
+		}
+		if !re.MatchString(value) {
+			return fmt.Errorf("%s must match %s", v.Name, v.Pattern)
+		}
 	}
 
 	return nil
 }
 
-// parse turns a list of bites into a list of templates
-func parse(s string) ([]Template, error) {
+// mappingValue returns the value of key in a YAML mapping node, or nil
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// decode unmarshals a list of templates, recording the YAML lines of each
+// template, of its text, of its variables and of its pages
+func decode(s string) ([]Template, error) {
+	var root yaml.Node
+
+	err := yaml.Unmarshal([]byte(s), &root)
+	if err != nil {
+		return nil, err
+	}
 
 	templates := []Template{}
 
-	err := yaml.Unmarshal([]byte(s), &templates)
+	// An empty document has no templates
+	if len(root.Content) == 0 {
+		return templates, nil
+	}
+
+	err = root.Decode(&templates)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, n := range root.Content[0].Content {
+		templates[i].line = n.Line
+
+		if text := mappingValue(n, "text"); text != nil {
+			templates[i].textLine = text.Line
+			// Block scalars start on the line after the indicator
+			if text.Style == yaml.LiteralStyle || text.Style == yaml.FoldedStyle {
+				templates[i].textLine++
+			}
+		}
+
+		if variables := mappingValue(n, "variables"); variables != nil {
+			for j, v := range variables.Content {
+				if j < len(templates[i].Variables) {
+					templates[i].Variables[j].line = v.Line
+				}
+			}
+		}
+
+		if pages := mappingValue(n, "pages"); pages != nil {
+			for j, p := range pages.Content {
+				if j < len(templates[i].Pages) {
+					templates[i].Pages[j].line = p.Line
+				}
+			}
+		}
+	}
+
+	return templates, nil
+}
+
+// parse turns a list of bites into a list of templates. Invalid templates are
+// left out and reported together as ValidationErrors.
+func parse(s string) ([]Template, error) {
+
+	templates, err := decode(s)
 
 	if err != nil {
 		return nil, fmt.Errorf("error parsing yaml: %v", err)
 	}
 
 	var validTemplates []Template
+	var errs ValidationErrors
 	for i, template := range templates {
 		err = template.validate(i)
 		if err != nil {
-			fmt.Println(err)
+			errs = append(errs, err.(ValidationErrors)...)
 		} else {
 			validTemplates = append(validTemplates, template)
 		}
 
 	}
-	return validTemplates, nil
+	return validTemplates, errs.orNil()
 
 }
 
@@ -109,7 +449,8 @@ func open(path string) (string, error) {
 	return string(data), nil
 }
 
-// read reads a file and returns a list of templates
+// read reads a file and returns a list of templates. Invalid templates are
+// reported as ValidationErrors along with the valid ones.
 func read(path string) ([]Template, error) {
 	data, err := open(path)
 
@@ -120,145 +461,357 @@ func read(path string) ([]Template, error) {
 	var templates []Template
 	templates, err = parse(data)
 
-	if err != nil {
+	if validationErrs, ok := err.(ValidationErrors); ok {
+		err = validationErrs.inFile(path)
+	} else if err != nil {
 		return nil, fmt.Errorf("error parsing file: %v", err)
 	}
 
-	return templates, nil
+	for i := range templates {
+		templates[i].Source = path
+	}
+
+	return templates, err
 }
 
-// ReadDefault reads the default template file
+// Read reads the templates of the template file at path. When path is a
+// directory, the 'comtemplate.yml' or 'comtemplate.yaml' file inside it is
+// read. Invalid templates are reported as ValidationErrors along with the
+// valid ones.
+func Read(path string) ([]Template, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config: %v", err)
+	}
+
+	if info.IsDir() && findFile(path) == "" {
+		return nil, fmt.Errorf("error reading config: no template file found in %s", path)
+	}
+
+	return read(ResolveFile(path))
+}
+
+// ReadDefault reads the templates of every template file found by
+// ConfigFiles. Templates from closer files win on name collisions. Invalid
+// templates are reported as ValidationErrors along with the valid ones.
 func ReadDefault() ([]Template, error) {
-	possible := []string{
-		"comtemplate.yml",
-		"comtemplate.yaml",
+	files, err := ConfigFiles()
+	if err != nil {
+		return nil, fmt.Errorf("Read Default: %v", err)
 	}
 
-	for _, path := range possible {
-		templates, err := read(path)
-		if err == nil {
-			return templates, nil
-		}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("Read Default: no default template found")
 	}
 
-	return nil, fmt.Errorf("Read Default: no default template found")
+	templates, err := readAll(files)
+	if _, ok := err.(ValidationErrors); ok {
+		return templates, err
+	} else if err != nil {
+		return nil, fmt.Errorf("Read Default: %v", err)
+	}
 
+	return templates, nil
 }
 
-// CreateDefault creates a default template file
+// CreateDefault creates a default template file in the current directory
 func CreateDefault() error {
-	defaultName := "comtemplate.yml"
-
-	defaultTemplate := []byte(`
-- name: 1
-  description: Simple commit message
-  text: |
-    %{description}
-
-    %{body}
-  variables:
-    - name: description 
-    - name: body
-      type: text
-- name: 2
-  description: Commit message with type
-  text: |
-    [%{type}] %{description}
-
-    %{body}
-  variables:
-    - name: type
-      type: select
-      options:
-        - ✨ feat
-        - 🐛 fix
-        - ♻️  refactor
-        - 📝 docs
-        - 🎨 style
-        - ✅ test
-        - ⚡️ perf
-    - name: description
-    - name: body
-      type: text
-`)
-	// Check if file exists
-	if _, err := os.Stat(defaultName); err == nil {
-		return fmt.Errorf("File %s already exists", defaultName)
-	}
-
-	err := os.WriteFile(defaultName, defaultTemplate, 0644)
+	_, err := CreatePreset(DefaultPreset, defaultNames[0], false)
+	return err
+}
 
-	if err != nil {
-		return fmt.Errorf("error creating default template: %v", err)
+// FillDefaults returns a copy of values where variables without a value are
+// set to their default, when they have one. Confirm variables default to no.
+// Computed variables are left out, since their default is only a fallback.
+func FillDefaults(template Template, values map[string]string) map[string]string {
+	filled := make(map[string]string)
+	for name, value := range values {
+		filled[name] = value
 	}
 
-	return nil
+	for _, variable := range template.Variables {
+		if _, ok := filled[variable.Name]; ok || variable.computed() {
+			continue
+		}
+		if variable.Default != "" {
+			filled[variable.Name] = variable.Default
+		} else if variable.Type == "confirm" {
+			filled[variable.Name] = "false"
+		}
+	}
+
+	return filled
 }
 
-// PopulateTemplate replaces variables in a template with values
+// PopulateTemplate replaces variables in a template with values. Variables
+// without a value use their default, and computed variables are computed from
+// the others, ignoring given values. Blocks written as %{?name}...%{/name} are
+// dropped when name is empty, and blank lines are normalized.
 func PopulateTemplate(template Template, variables map[string]string) (string, error) {
-	text := template.Text
+	variables = FillDefaults(template, variables)
 
+	var errBuilder strings.Builder
 	for _, variable := range template.Variables {
 		value, ok := variables[variable.Name]
+		if !ok || variable.computed() {
+			continue
+		}
+		value, err := variable.normalize(value)
+		if err == nil {
+			err = variable.check(value)
+		}
+		if err != nil {
+			fmt.Fprintf(&errBuilder, "%v\n", err)
+		}
+		variables[variable.Name] = value
+	}
+
+	if errBuilder.Len() > 0 {
+		return "", fmt.Errorf("invalid values:\n%s", errBuilder.String())
+	}
 
-		if !ok {
+	for _, variable := range template.Variables {
+		if _, ok := variables[variable.Name]; !ok && !variable.computed() {
 			return "", fmt.Errorf("variable %s not found", variable.Name)
 		}
+	}
+
+	if err := computeValues(template, variables); err != nil {
+		return "", err
+	}
+
+	for _, variable := range template.Variables {
+		if !variable.computed() {
+			continue
+		}
+		if err := variable.check(variables[variable.Name]); err != nil {
+			return "", fmt.Errorf("invalid computed value: %v", err)
+		}
+	}
+
+	nodes, err := parseText(template.Text)
+	if err != nil {
+		return "", fmt.Errorf("error parsing template text: %v", err)
+	}
 
-		varName := fmt.Sprintf("%%{%s}", variable.Name)
+	return normalizeBlankLines(render(nodes, variables)), nil
+}
+
+// SelectTemplate asks which of templates to use. Typing '/' filters the list
+// fuzzily by name and description.
+func SelectTemplate(templates []Template) (Template, error) {
+	if len(templates) == 0 {
+		return Template{}, fmt.Errorf("no templates to select from")
+	}
 
-		text = strings.Replace(text, varName, value, -1)
+	items := make([]pickerItem, len(templates))
+	for i, template := range templates {
+		items[i] = pickerItem{title: template.Name, description: template.Description, index: i}
+	}
+
+	selected, err := runPicker("Template", items)
+	if err != nil {
+		return Template{}, err
 	}
 
-	return text, nil
+	return templates[selected], nil
 }
 
-func PopulateFromForm(template Template) (string, error) {
-	var variables = make(map[string]string)
-	var inputList []huh.Field
-
-	// Create a slice for intermediate storage
-	inputValues := make([]string, len(template.Variables))
-
-	for i, variable := range template.Variables {
-		var input huh.Field
-		switch variable.Type {
-		case "input", "":
-			input = huh.NewInput().
-				Title(variable.Name).
-				Value(&inputValues[i])
-		case "text":
-			input = huh.NewText().
-				Title(variable.Name).
-				Value(&inputValues[i])
-		case "select":
-			var options = make([]huh.Option[string], len(variable.Options))
-			for i, option := range variable.Options {
-				options[i] = huh.NewOption(option, option)
+// title returns the title of the variable in the form
+func (v Variable) title() string {
+	if v.Title != "" {
+		return v.Title
+	}
+	return v.Name
+}
+
+// field returns the form field asking for the variable, storing the answer in
+// value. Fields not answered with text come with a function to call to store
+// the answer, and the function is nil otherwise.
+func (v Variable) field(value *string) (huh.Field, func(), error) {
+	switch v.Type {
+	case "confirm", "multiselect":
+		field, store := v.typedField(value)
+		return field, store, nil
+	case "number", "date":
+		placeholder := v.Placeholder
+		if v.Type == "date" {
+			if date, err := v.normalize(*value); err == nil {
+				*value = date
+			}
+			if placeholder == "" {
+				placeholder = v.dateFormat()
 			}
-			input = huh.NewSelect[string]().
-				Title(variable.Name).
-				Options(options...).
-				Value(&inputValues[i])
-		default:
-			return "", fmt.Errorf("unknown variable type: %s", variable.Type)
 		}
-		inputList = append(inputList, input)
+		return huh.NewInput().
+			Title(v.title()).
+			Description(v.Description).
+			Placeholder(placeholder).
+			Validate(v.check).
+			Value(value), nil, nil
+	case "input", "":
+		field := huh.NewInput().
+			Title(v.title()).
+			Description(v.Description).
+			Placeholder(v.Placeholder).
+			Validate(v.check).
+			Value(value)
+		if v.MaxLength > 0 {
+			field.CharLimit(v.MaxLength)
+		}
+		return field, nil, nil
+	case "text":
+		field := huh.NewText().
+			Title(v.title()).
+			Description(v.Description).
+			Placeholder(v.Placeholder).
+			Validate(v.check).
+			Value(value)
+		if v.MaxLength > 0 {
+			field.CharLimit(v.MaxLength)
+		}
+		return field, nil, nil
+	case "select":
+		var options = make([]huh.Option[string], len(v.allOptions()))
+		for i, option := range v.allOptions() {
+			options[i] = huh.NewOption(option, option)
+		}
+		return huh.NewSelect[string]().
+			Title(v.title()).
+			Description(v.Description).
+			Options(options...).
+			Validate(v.check).
+			Value(value), nil, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown variable type: %s", v.Type)
 	}
+}
 
-	form := huh.NewForm(huh.NewGroup(inputList...))
+// formGroups returns the pages of the form asking for the missing variables.
+// Pages without missing variables are left out.
+func formGroups(template Template, missing []Variable, fields map[string]huh.Field) []*huh.Group {
+	var groups []*huh.Group
+	onPage := make(map[string]bool)
+
+	for _, page := range template.Pages {
+		var pageFields []huh.Field
+		for _, name := range page.Variables {
+			onPage[name] = true
+			if field, ok := fields[name]; ok {
+				pageFields = append(pageFields, field)
+			}
+		}
 
-	err := form.Run()
+		if len(pageFields) == 0 {
+			continue
+		}
+
+		// Groups do not show their title, so it is shown by a note
+		if page.Title != "" || page.Description != "" {
+			note := huh.NewNote().
+				Title(page.Title).
+				Description(page.Description).
+				Next(true)
+			pageFields = append([]huh.Field{note}, pageFields...)
+		}
+		groups = append(groups, huh.NewGroup(pageFields...))
+	}
+
+	var rest []huh.Field
+	for _, variable := range missing {
+		if !onPage[variable.Name] {
+			rest = append(rest, fields[variable.Name])
+		}
+	}
+	if len(rest) > 0 {
+		groups = append(groups, huh.NewGroup(rest...))
+	}
+
+	return groups
+}
 
+// templateForm is the form asking for the variables of a template missing
+// from the given values
+type templateForm struct {
+	// form is nil when no variable is missing
+	form *huh.Form
+
+	given   map[string]string
+	missing []Variable
+	// inputValues holds the answers, prefilled with the defaults
+	inputValues []string
+	// stores store the answers of fields not answered with text in
+	// inputValues
+	stores []func()
+}
+
+// newTemplateForm creates the form asking for the variables of template
+// missing from values
+func newTemplateForm(template Template, values map[string]string) (*templateForm, error) {
+	f := &templateForm{given: make(map[string]string)}
+	for name, value := range values {
+		f.given[name] = value
+	}
+
+	f.missing = MissingVariables(template, f.given)
+	if len(f.missing) == 0 {
+		return f, nil
+	}
+
+	f.inputValues = make([]string, len(f.missing))
+	for i, variable := range f.missing {
+		f.inputValues[i] = variable.Default
+	}
+
+	fields := make(map[string]huh.Field)
+	for i, variable := range f.missing {
+		field, store, err := variable.field(&f.inputValues[i])
+		if err != nil {
+			return nil, err
+		}
+		fields[variable.Name] = field
+		if store != nil {
+			f.stores = append(f.stores, store)
+		}
+	}
+
+	f.form = huh.NewForm(formGroups(template, f.missing, fields)...)
+
+	return f, nil
+}
+
+// values returns the given values together with the answers of the form
+func (f *templateForm) values() map[string]string {
+	for _, store := range f.stores {
+		store()
+	}
+
+	variables := make(map[string]string)
+	for name, value := range f.given {
+		variables[name] = value
+	}
+
+	for i, variable := range f.missing {
+		variables[variable.Name] = f.inputValues[i]
+	}
+
+	return variables
+}
+
+// PopulateFromForm asks for the variables of template missing from values
+// with a form and populates the template with the result
+func PopulateFromForm(template Template, values map[string]string) (string, error) {
+	f, err := newTemplateForm(template, values)
 	if err != nil {
-		return "", fmt.Errorf("error running form: %v", err)
+		return "", err
 	}
 
-	// Update the map with the values from the form
-	for i, variable := range template.Variables {
-		variables[variable.Name] = inputValues[i]
+	if f.form != nil {
+		err = runForm(f.form)
+		if err != nil {
+			return "", fmt.Errorf("error running form: %v", err)
+		}
 	}
 
-	return PopulateTemplate(template, variables)
+	return PopulateTemplate(template, f.values())
 }
@@ -3,7 +3,10 @@ package cli
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/charmbracelet/huh"
 )
 
 var mockData = `
@@ -272,6 +275,183 @@ func TestPopulateTemplate(t *testing.T) {
 	}
 }
 
+func TestPopulateTemplateDefaults(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "[%{type}] %{title}",
+		Variables: []Variable{
+			{Name: "type", Type: "select", Options: []string{"feat", "fix"}, Default: "feat"},
+			{Name: "title"},
+		},
+	}
+
+	t.Run("should use the default for missing values", func(t *testing.T) {
+		got, err := PopulateTemplate(template, map[string]string{"title": "Test title"})
+		if err != nil {
+			t.Fatalf("error populating template: %v", err)
+		}
+
+		if got != "[feat] Test title" {
+			t.Errorf("expected string to be '[feat] Test title', got '%s'", got)
+		}
+	})
+
+	t.Run("should prefer given values", func(t *testing.T) {
+		got, err := PopulateTemplate(template, map[string]string{"type": "fix", "title": "Test title"})
+		if err != nil {
+			t.Fatalf("error populating template: %v", err)
+		}
+
+		if got != "[fix] Test title" {
+			t.Errorf("expected string to be '[fix] Test title', got '%s'", got)
+		}
+	})
+
+	t.Run("should fail for missing values without default", func(t *testing.T) {
+		if _, err := PopulateTemplate(template, map[string]string{}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+func TestCheckVariable(t *testing.T) {
+	testCases := []struct {
+		description string
+		v           Variable
+		value       string
+		want        string
+	}{
+		{
+			description: "should accept empty optional values",
+			v:           Variable{Name: "scope", MinLength: 3, Pattern: "^[a-z]+$"},
+			value:       "",
+		},
+		{
+			description: "should reject empty required values",
+			v:           Variable{Name: "description", Required: true},
+			value:       "",
+			want:        "description is required",
+		},
+		{
+			description: "should reject short values",
+			v:           Variable{Name: "description", MinLength: 5},
+			value:       "fix",
+			want:        "description must be at least 5 characters long",
+		},
+		{
+			description: "should count characters, not bytes",
+			v:           Variable{Name: "type", MaxLength: 6},
+			value:       "✨ feat",
+		},
+		{
+			description: "should reject long values",
+			v:           Variable{Name: "description", MaxLength: 5},
+			value:       "too long",
+			want:        "description must be at most 5 characters long",
+		},
+		{
+			description: "should reject values not matching the pattern",
+			v:           Variable{Name: "ticket", Pattern: "^[A-Z]+-[0-9]+$"},
+			value:       "proj-1",
+			want:        "ticket must match ^[A-Z]+-[0-9]+$",
+		},
+		{
+			description: "should accept values matching the pattern",
+			v:           Variable{Name: "ticket", Pattern: "^[A-Z]+-[0-9]+$"},
+			value:       "PROJ-1",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := tc.v.check(tc.value)
+			if tc.want == "" {
+				if err != nil {
+					t.Errorf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			if err == nil || err.Error() != tc.want {
+				t.Errorf("expected error '%s', got %v", tc.want, err)
+			}
+		})
+	}
+}
+
+func TestPopulateTemplateChecksValues(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "%{title}",
+		Variables: []Variable{
+			{Name: "title", Required: true},
+		},
+	}
+
+	_, err := PopulateTemplate(template, map[string]string{"title": ""})
+	if err == nil || !strings.Contains(err.Error(), "title is required") {
+		t.Errorf("expected error naming title, got %v", err)
+	}
+}
+
+func TestPopulateTemplateChecksOptions(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "[%{type}] %{title}",
+		Variables: []Variable{
+			{Name: "type", Type: "select", Options: []string{"feat", "fix"}},
+			{Name: "title"},
+		},
+	}
+
+	_, err := PopulateTemplate(template, map[string]string{"type": "bogus", "title": "x"})
+	if err == nil || !strings.Contains(err.Error(), "type must be one of: feat, fix") {
+		t.Errorf("expected error naming the options of type, got %v", err)
+	}
+
+	got, err := PopulateTemplate(template, map[string]string{"type": "fix", "title": "x"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "[fix] x" {
+		t.Errorf("expected %q, got %q", "[fix] x", got)
+	}
+}
+
+func TestValidateLines(t *testing.T) {
+	data := `
+- name: Lines
+  text: |
+    [%{type}] %{title}
+
+    %{scope}
+  variables:
+    - name: type
+    - name: title
+    - name: title
+`
+
+	templates, err := decode(data)
+	if err != nil {
+		t.Fatalf("error decoding yaml: %v", err)
+	}
+
+	err = templates[0].validate(0)
+	if err == nil {
+		t.Fatalf("expected error, got nil")
+	}
+
+	wantLines := []string{
+		"Template 0 (line 6): placeholder %{scope} has no matching variable",
+		"Template 0 - Variable 2 (line 10): variable title is declared more than once",
+	}
+	for _, want := range wantLines {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain '%s', got '%s'", want, err)
+		}
+	}
+}
+
 func TestValidateTemplate(t *testing.T) {
 	testCases := []struct {
 		id          int
@@ -350,6 +530,327 @@ func TestValidateTemplate(t *testing.T) {
 				},
 			},
 		},
+		{
+			id:          7,
+			description: "should return true for select default in options",
+			t: Template{
+				Name: "Test",
+				Text: "Test %{test}",
+				Variables: []Variable{
+					{Name: "test", Type: "select", Options: []string{"a", "b"}, Default: "b"},
+				},
+			},
+			want: true,
+		},
+		{
+			id:          8,
+			description: "should return false for select default not in options",
+			t: Template{
+				Name: "Test",
+				Text: "Test %{test}",
+				Variables: []Variable{
+					{Name: "test", Type: "select", Options: []string{"a", "b"}, Default: "c"},
+				},
+			},
+			want: false,
+		},
+		{
+			id:          9,
+			description: "should return false for invalid pattern",
+			t: Template{
+				Name: "Test",
+				Text: "Test %{test}",
+				Variables: []Variable{
+					{Name: "test", Pattern: "("},
+				},
+			},
+			want: false,
+		},
+		{
+			id:          10,
+			description: "should return false for min_length greater than max_length",
+			t: Template{
+				Name: "Test",
+				Text: "Test %{test}",
+				Variables: []Variable{
+					{Name: "test", MinLength: 10, MaxLength: 5},
+				},
+			},
+			want: false,
+		},
+		{
+			id:          11,
+			description: "should return false for unclosed block",
+			t: Template{
+				Name: "Test",
+				Text: "Test %{?test}%{test}",
+				Variables: []Variable{
+					{Name: "test"},
+				},
+			},
+			want: false,
+		},
+		{
+			id:          12,
+			description: "should return true for variable used only as block condition",
+			t: Template{
+				Name: "Test",
+				Text: "Test %{?breaking}BREAKING CHANGE%{/breaking}",
+				Variables: []Variable{
+					{Name: "breaking"},
+				},
+			},
+			want: true,
+		},
+		{
+			id:          13,
+			description: "should return false for undeclared placeholder",
+			t: Template{
+				Name: "Test",
+				Text: "[%{scope}] %{test}",
+				Variables: []Variable{
+					{Name: "test"},
+				},
+			},
+			want: false,
+		},
+		{
+			id:          14,
+			description: "should return false for duplicated variable",
+			t: Template{
+				Name: "Test",
+				Text: "Test %{test}",
+				Variables: []Variable{
+					{Name: "test"},
+					{Name: "test", Type: "text"},
+				},
+			},
+			want: false,
+		},
+		{
+			id:          15,
+			description: "should return false for reserved variable name",
+			t: Template{
+				Name: "Test",
+				Text: "Test %{test}",
+				Variables: []Variable{
+					{Name: "test"},
+					{Name: "a|b"},
+				},
+			},
+			want: false,
+		},
+		{
+			id:          16,
+			description: "should return true for valid pages",
+			t: Template{
+				Name: "Test",
+				Text: "%{title}\n\n%{body}",
+				Variables: []Variable{
+					{Name: "title", Title: "Summary", Description: "What changed", Placeholder: "Add pages"},
+					{Name: "body", Type: "text"},
+				},
+				Pages: []Page{
+					{Title: "Summary", Variables: []string{"title"}},
+					{Title: "Details", Variables: []string{"body"}},
+				},
+			},
+			want: true,
+		},
+		{
+			id:          17,
+			description: "should return false for page with undeclared variable",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test"}},
+				Pages:     []Page{{Variables: []string{"test", "missing"}}},
+			},
+			want: false,
+		},
+		{
+			id:          18,
+			description: "should return false for variable on two pages",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test"}},
+				Pages:     []Page{{Variables: []string{"test"}}, {Variables: []string{"test"}}},
+			},
+			want: false,
+		},
+		{
+			id:          19,
+			description: "should return false for empty page",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test"}},
+				Pages:     []Page{{Title: "Empty"}},
+			},
+			want: false,
+		},
+		{
+			id:          20,
+			description: "should return true for branch source",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", Source: "branch", Regex: `([A-Z]+-[0-9]+)`}},
+			},
+			want: true,
+		},
+		{
+			id:          21,
+			description: "should return false for unknown source",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", Source: "tag"}},
+			},
+			want: false,
+		},
+		{
+			id:          22,
+			description: "should return false for invalid regex",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", Source: "branch", Regex: "("}},
+			},
+			want: false,
+		},
+		{
+			id:          23,
+			description: "should return true for options from staged paths",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", Type: "select", OptionsFrom: "staged_paths", Paths: []string{"services/billing/** -> billing"}}},
+			},
+			want: true,
+		},
+		{
+			id:          24,
+			description: "should return false for invalid path rule",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", Type: "select", OptionsFrom: "staged_paths", Paths: []string{"services/billing/**"}}},
+			},
+			want: false,
+		},
+		{
+			id:          25,
+			description: "should return false for options from staged paths on input",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", OptionsFrom: "staged_paths", Paths: []string{"docs/** -> docs"}}},
+			},
+			want: false,
+		},
+		{
+			id:          26,
+			description: "should return true for options from a command and a file",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test} %{other}",
+				Variables: []Variable{{Name: "test", Type: "select", OptionsCommand: "git branch --format='%(refname:short)'", OptionsTimeout: "2s", OptionsCache: "10m", Default: "main"}, {Name: "other", Type: "multiselect", OptionsFile: "teams.txt"}},
+			},
+			want: true,
+		},
+		{
+			id:          27,
+			description: "should return false for options command on input",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", OptionsCommand: "ls"}},
+			},
+			want: false,
+		},
+		{
+			id:          28,
+			description: "should return false for invalid options timeout",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", Type: "select", OptionsCommand: "ls", OptionsTimeout: "soon"}},
+			},
+			want: false,
+		},
+		{
+			id:          29,
+			description: "should return false for options cache without command",
+			t: Template{
+				Name:      "Test",
+				Text:      "Test %{test}",
+				Variables: []Variable{{Name: "test", Type: "select", OptionsFile: "teams.txt", OptionsCache: "1h"}},
+			},
+			want: false,
+		},
+		{
+			id:          30,
+			description: "should return true for computed variables",
+			t: Template{
+				Name:      "Test",
+				Text:      "%{emoji} %{type} %{refs}",
+				Variables: []Variable{{Name: "emoji", Type: "computed", From: "type", Map: map[string]string{"feat": "✨"}}, {Name: "type"}, {Name: "ticket"}, {Name: "refs", Type: "computed", Value: "Refs: %{ticket}"}},
+			},
+			want: true,
+		},
+		{
+			id:          31,
+			description: "should return false for computed variables depending on each other",
+			t: Template{
+				Name:      "Test",
+				Text:      "%{a} %{b}",
+				Variables: []Variable{{Name: "a", Type: "computed", Value: "%{b}"}, {Name: "b", Type: "computed", From: "a", Map: map[string]string{"x": "y"}}},
+			},
+			want: false,
+		},
+		{
+			id:          32,
+			description: "should return false for computed variable depending on undeclared variable",
+			t: Template{
+				Name:      "Test",
+				Text:      "%{refs}",
+				Variables: []Variable{{Name: "refs", Type: "computed", Value: "Refs: %{ticket}"}},
+			},
+			want: false,
+		},
+		{
+			id:          33,
+			description: "should return false for computed variable with from but no map",
+			t: Template{
+				Name:      "Test",
+				Text:      "%{emoji} %{type}",
+				Variables: []Variable{{Name: "emoji", Type: "computed", From: "type"}, {Name: "type"}},
+			},
+			want: false,
+		},
+		{
+			id:          34,
+			description: "should return false for computed variable on a page",
+			t: Template{
+				Name:      "Test",
+				Text:      "%{refs} %{ticket}",
+				Variables: []Variable{{Name: "ticket"}, {Name: "refs", Type: "computed", Value: "Refs: %{ticket}"}},
+				Pages:     []Page{{Title: "Refs", Variables: []string{"ticket", "refs"}}},
+			},
+			want: false,
+		},
+		{
+			id:          35,
+			description: "should return false for map on a variable that is not computed",
+			t: Template{
+				Name:      "Test",
+				Text:      "%{emoji}",
+				Variables: []Variable{{Name: "emoji", Map: map[string]string{"feat": "✨"}}},
+			},
+			want: false,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -367,3 +868,78 @@ func TestValidateTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestFormGroups(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "%{title}\n\n%{body}\n\n%{footer}",
+		Variables: []Variable{
+			{Name: "title"},
+			{Name: "body", Type: "text"},
+			{Name: "footer"},
+		},
+		Pages: []Page{
+			{Title: "Summary", Variables: []string{"title"}},
+			{Title: "Details", Variables: []string{"body"}},
+		},
+	}
+
+	fields := func(missing []Variable) map[string]huh.Field {
+		values := make([]string, len(missing))
+		result := make(map[string]huh.Field)
+		for i, variable := range missing {
+			field, _, err := variable.field(&values[i])
+			if err != nil {
+				t.Fatalf("error creating field: %v", err)
+			}
+			result[variable.Name] = field
+		}
+		return result
+	}
+
+	t.Run("should add a page for variables left out", func(t *testing.T) {
+		missing := template.Variables
+		if got := len(formGroups(template, missing, fields(missing))); got != 3 {
+			t.Errorf("expected 3 pages, got %d", got)
+		}
+	})
+
+	t.Run("should skip pages without missing variables", func(t *testing.T) {
+		missing := template.Variables[1:2]
+		if got := len(formGroups(template, missing, fields(missing))); got != 1 {
+			t.Errorf("expected 1 page, got %d", got)
+		}
+	})
+}
+
+func TestDecodePages(t *testing.T) {
+	data := `
+- name: Pages
+  text: "%{title}"
+  variables:
+    - name: title
+      title: Summary
+      description: What changed
+      placeholder: Add pages
+  pages:
+    - title: Summary
+      variables: [title]
+    - title: Empty
+`
+
+	templates, err := decode(data)
+	if err != nil {
+		t.Fatalf("error decoding yaml: %v", err)
+	}
+
+	variable := templates[0].Variables[0]
+	if variable.Title != "Summary" || variable.Description != "What changed" || variable.Placeholder != "Add pages" {
+		t.Errorf("expected title, description and placeholder to be decoded, got %+v", variable)
+	}
+
+	err = templates[0].validate(0)
+	want := "Template 0 (line 12): page 1 has no variables"
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("expected error to contain '%s', got '%v'", want, err)
+	}
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+)
+
+// pickerHeight is the height of the picker until the size of the terminal is
+// known
+const pickerHeight = 20
+
+// pickerItem is an entry of the picker
+type pickerItem struct {
+	title       string
+	description string
+	index       int
+}
+
+func (i pickerItem) Title() string       { return i.title }
+func (i pickerItem) Description() string { return i.description }
+
+// FilterValue is matched fuzzily against the filter, so that "cnvtl" finds
+// "conventional"
+func (i pickerItem) FilterValue() string {
+	return i.title + " " + i.description
+}
+
+// pickerModel is a list whose entries are filtered fuzzily after typing '/'.
+// It quits once an entry is chosen or the list is aborted.
+type pickerModel struct {
+	list    list.Model
+	chosen  int
+	aborted bool
+	done    bool
+}
+
+// newPicker returns a picker titled title listing items
+func newPicker(title string, items []pickerItem) pickerModel {
+	listItems := make([]list.Item, len(items))
+	for i, item := range items {
+		listItems[i] = item
+	}
+
+	l := list.New(listItems, list.NewDefaultDelegate(), defaultWidth, pickerHeight)
+	l.Title = title
+	l.Filter = list.DefaultFilter
+	l.SetShowStatusBar(false)
+	l.DisableQuitKeybindings()
+
+	return pickerModel{list: l, chosen: -1}
+}
+
+func (m pickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.list.SetSize(msg.Width, min(msg.Height, pickerHeight))
+	case tea.KeyMsg:
+		if key.Matches(msg, key.NewBinding(key.WithKeys("ctrl+c"))) {
+			m.aborted, m.done = true, true
+			return m, tea.Quit
+		}
+
+		// Keys typed in the filter belong to it
+		if m.list.SettingFilter() {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			if item, ok := m.list.SelectedItem().(pickerItem); ok {
+				m.chosen, m.done = item.index, true
+				return m, tea.Quit
+			}
+		case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))) && !m.list.IsFiltered():
+			m.aborted, m.done = true, true
+			return m, tea.Quit
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m pickerModel) View() string {
+	if m.done {
+		return ""
+	}
+	return m.list.View()
+}
+
+// runPicker asks which of items to choose and returns its index. It returns
+// huh.ErrUserAborted when the picker is aborted.
+func runPicker(title string, items []pickerItem) (int, error) {
+	model, err := tea.NewProgram(newPicker(title, items), tea.WithOutput(output)).Run()
+	if err != nil {
+		return 0, err
+	}
+
+	picker := model.(pickerModel)
+	if picker.aborted || picker.chosen < 0 {
+		return 0, huh.ErrUserAborted
+	}
+
+	return picker.chosen, nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// sendKeys sends keys to the picker, feeding back the messages of the commands
+// that answer at once, such as the filter results
+func sendKeys(m pickerModel, keys ...tea.KeyMsg) pickerModel {
+	var run func(cmd tea.Cmd)
+	run = func(cmd tea.Cmd) {
+		if cmd == nil {
+			return
+		}
+
+		result := make(chan tea.Msg, 1)
+		go func() { result <- cmd() }()
+
+		select {
+		case msg := <-result:
+			if batch, ok := msg.(tea.BatchMsg); ok {
+				for _, cmd := range batch {
+					run(cmd)
+				}
+				return
+			}
+			if msg == nil {
+				return
+			}
+			model, next := m.Update(msg)
+			m = model.(pickerModel)
+			run(next)
+		case <-time.After(50 * time.Millisecond):
+			// Timers, such as the cursor blink, are not waited for
+		}
+	}
+
+	for _, k := range keys {
+		model, cmd := m.Update(k)
+		m = model.(pickerModel)
+		run(cmd)
+	}
+
+	return m
+}
+
+// typed returns the keys typing s
+func typed(s string) []tea.KeyMsg {
+	var keys []tea.KeyMsg
+	for _, r := range s {
+		keys = append(keys, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+	}
+	return keys
+}
+
+func TestPicker(t *testing.T) {
+	items := []pickerItem{
+		{title: "angular", description: "Angular commit message", index: 0},
+		{title: "conventional", description: "Conventional commits", index: 1},
+		{title: "jira", description: "Message starting with a Jira issue key", index: 2},
+	}
+
+	enter := tea.KeyMsg{Type: tea.KeyEnter}
+
+	t.Run("should choose the selected entry", func(t *testing.T) {
+		m := sendKeys(newPicker("Template", items), tea.KeyMsg{Type: tea.KeyDown}, enter)
+		if !m.done || m.chosen != 1 {
+			t.Errorf("expected conventional to be chosen, got %d", m.chosen)
+		}
+	})
+
+	t.Run("should filter fuzzily", func(t *testing.T) {
+		keys := append(typed("/cnvtl"), enter, enter)
+		m := sendKeys(newPicker("Template", items), keys...)
+		if !m.done || m.chosen != 1 {
+			t.Errorf("expected conventional to be chosen, got %d", m.chosen)
+		}
+	})
+
+	t.Run("should filter on the description", func(t *testing.T) {
+		keys := append(typed("/issuekey"), enter, enter)
+		m := sendKeys(newPicker("Template", items), keys...)
+		if !m.done || m.chosen != 2 {
+			t.Errorf("expected jira to be chosen, got %d", m.chosen)
+		}
+	})
+
+	t.Run("should abort", func(t *testing.T) {
+		m := sendKeys(newPicker("Template", items), tea.KeyMsg{Type: tea.KeyEsc})
+		if !m.aborted {
+			t.Errorf("expected picker to be aborted")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/huh"
+)
+
+// DefaultPreset is the preset written when none is chosen
+const DefaultPreset = "default"
+
+// presetFiles holds the template files that can be written by ct init
+//
+//go:embed presets/*.yml
+var presetFiles embed.FS
+
+// Presets returns the names of the available presets, sorted
+func Presets() []string {
+	entries, _ := presetFiles.ReadDir("presets")
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".yml"))
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Preset returns the content of the preset with the given name
+func Preset(name string) ([]byte, error) {
+	data, err := presetFiles.ReadFile("presets/" + name + ".yml")
+	if err != nil {
+		return nil, fmt.Errorf("unknown preset '%s': expected one of %s", name, strings.Join(Presets(), ", "))
+	}
+	return data, nil
+}
+
+// presetDescription returns the description of a preset, given by the comment
+// on its first line
+func presetDescription(name string) string {
+	data, err := Preset(name)
+	if err != nil {
+		return ""
+	}
+
+	line, _, _ := strings.Cut(string(data), "\n")
+	return strings.TrimSpace(strings.TrimPrefix(line, "#"))
+}
+
+// SelectPreset asks which preset to write
+func SelectPreset() (string, error) {
+	var options []huh.Option[string]
+	for _, name := range Presets() {
+		options = append(options, huh.NewOption(fmt.Sprintf("%s - %s", name, presetDescription(name)), name))
+	}
+
+	name := DefaultPreset
+	form := huh.NewForm(huh.NewGroup(
+		huh.NewSelect[string]().
+			Title("Preset").
+			Options(options...).
+			Value(&name),
+	))
+
+	if err := runForm(form); err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// CreatePreset writes the preset with the given name to path, or to
+// comtemplate.yml in path when it is a directory or ends with a separator.
+// Existing files are only overwritten when force is true.
+func CreatePreset(name, path string, force bool) (string, error) {
+	data, err := Preset(name)
+	if err != nil {
+		return "", err
+	}
+
+	isDir := strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(filepath.Separator))
+	if info, err := os.Stat(path); isDir || (err == nil && info.IsDir()) {
+		path = filepath.Join(path, defaultNames[0])
+	}
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return "", fmt.Errorf("File %s already exists", path)
+	}
+
+	err = os.MkdirAll(filepath.Dir(path), 0755)
+	if err != nil {
+		return "", fmt.Errorf("error creating directory: %v", err)
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return "", fmt.Errorf("error creating template file: %v", err)
+	}
+
+	return path, nil
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestPresets(t *testing.T) {
+	names := Presets()
+
+	for _, want := range []string{"angular", "conventional", "default", "gitmoji", "jira", "minimal"} {
+		if !slices.Contains(names, want) {
+			t.Errorf("expected preset %s, got %v", want, names)
+		}
+	}
+
+	for _, name := range names {
+		t.Run(name, func(t *testing.T) {
+			data, err := Preset(name)
+			if err != nil {
+				t.Fatalf("error reading preset: %v", err)
+			}
+
+			templates, err := parse(string(data))
+			if err != nil {
+				t.Fatalf("expected preset to be valid, got %v", err)
+			}
+
+			if len(templates) == 0 {
+				t.Errorf("expected templates, got none")
+			}
+
+			if presetDescription(name) == "" {
+				t.Errorf("expected a description")
+			}
+		})
+	}
+
+	if _, err := Preset("unknown"); err == nil {
+		t.Errorf("expected error for unknown preset, got nil")
+	}
+}
+
+func TestConventionalPreset(t *testing.T) {
+	data, err := Preset("conventional")
+	if err != nil {
+		t.Fatalf("error reading preset: %v", err)
+	}
+
+	templates, err := parse(string(data))
+	if err != nil {
+		t.Fatalf("error parsing preset: %v", err)
+	}
+
+	values := map[string]string{
+		"type":        "feat",
+		"scope":       "cli",
+		"description": "add presets",
+		"breaking":    "init writes the chosen preset",
+		"body":        "",
+		"footer":      "Refs: #17",
+	}
+
+	got, err := PopulateTemplate(templates[0], values)
+	if err != nil {
+		t.Fatalf("error populating template: %v", err)
+	}
+
+	want := "feat(cli)!: add presets\n\nBREAKING CHANGE: init writes the chosen preset\nRefs: #17\n"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	match, err := MatchMessage(templates, got)
+	if err != nil {
+		t.Fatalf("error matching message: %v", err)
+	}
+	if !match.OK {
+		t.Errorf("expected message to match its template, got %s", match)
+	}
+}
+
+func TestCreatePreset(t *testing.T) {
+	tempDir := t.TempDir()
+
+	t.Run("should write to a directory", func(t *testing.T) {
+		path, err := CreatePreset("minimal", tempDir, false)
+		if err != nil {
+			t.Fatalf("error creating preset: %v", err)
+		}
+
+		if want := filepath.Join(tempDir, "comtemplate.yml"); path != want {
+			t.Errorf("expected path %s, got %s", want, path)
+		}
+	})
+
+	t.Run("should refuse to overwrite", func(t *testing.T) {
+		if _, err := CreatePreset("jira", tempDir, false); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+
+	t.Run("should overwrite with force", func(t *testing.T) {
+		path, err := CreatePreset("jira", tempDir, true)
+		if err != nil {
+			t.Fatalf("error creating preset: %v", err)
+		}
+
+		templates, err := read(path)
+		if err != nil {
+			t.Fatalf("error reading file: %v", err)
+		}
+		if templates[0].Name != "jira" {
+			t.Errorf("expected jira template, got %s", templates[0].Name)
+		}
+	})
+
+	t.Run("should create missing directories", func(t *testing.T) {
+		path := filepath.Join(tempDir, "tools", "commit.yml")
+		if _, err := CreatePreset("conventional", path, false); err != nil {
+			t.Fatalf("error creating preset: %v", err)
+		}
+
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected file to exist, got %v", err)
+		}
+	})
+}
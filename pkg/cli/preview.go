@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// subjectLimit is the recommended length of the subject line
+	subjectLimit = 50
+	// subjectHardLimit is the length after which tools truncate the subject
+	subjectHardLimit = 72
+	// defaultWidth is used until the size of the terminal is known
+	defaultWidth = 100
+)
+
+// Review actions offered once the message is rendered
+const (
+	reviewConfirm = "confirm"
+	reviewEdit    = "edit"
+	reviewCancel  = "cancel"
+)
+
+var previewBox = lipgloss.NewStyle().
+	Border(lipgloss.RoundedBorder()).
+	BorderForeground(subtle).
+	Padding(0, 1)
+
+// previewText renders template with values without checking them.
+// Placeholders of variables without a value are kept as they are written.
+func previewText(template Template, values map[string]string) string {
+	nodes, err := parseText(template.Text)
+	if err != nil {
+		return template.Text
+	}
+
+	filled := make(map[string]string)
+	for _, variable := range template.Variables {
+		value, err := variable.normalize(values[variable.Name])
+		if err == nil && value != "" && !variable.computed() {
+			filled[variable.Name] = value
+		}
+	}
+
+	_ = computeValues(template, filled)
+	for _, variable := range template.Variables {
+		if variable.computed() && filled[variable.Name] == "" {
+			delete(filled, variable.Name)
+		}
+	}
+
+	return normalizeBlankLines(render(nodes, filled))
+}
+
+// subjectWarnings returns warnings about the subject line of a commit
+// message, and whether one of them is serious
+func subjectWarnings(text string) ([]string, bool) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	length := utf8.RuneCountInString(lines[0])
+
+	var warnings []string
+	serious := false
+
+	switch {
+	case length > subjectHardLimit:
+		warnings = append(warnings, fmt.Sprintf("Subject is %d characters long, tools truncate it after %d", length, subjectHardLimit))
+		serious = true
+	case length > subjectLimit:
+		warnings = append(warnings, fmt.Sprintf("Subject is %d characters long, %d or fewer is recommended", length, subjectLimit))
+	}
+
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		warnings = append(warnings, "Separate the subject from the body with a blank line")
+	}
+
+	return warnings, serious
+}
+
+// renderPreview renders the preview pane for text with the given width
+func renderPreview(text string, width int) string {
+	items := []string{TextHighlight("Preview"), "", strings.TrimRight(text, "\n")}
+
+	warnings, serious := subjectWarnings(text)
+	if len(warnings) > 0 {
+		items = append(items, "")
+	}
+	for _, warning := range warnings {
+		if serious {
+			items = append(items, TextError("! "+warning))
+		} else {
+			items = append(items, TextSubtle("! "+warning))
+		}
+	}
+
+	return previewBox.Width(width).Render(lipgloss.JoinVertical(lipgloss.Left, items...))
+}
+
+// previewModel shows a form next to a live preview of the message it
+// produces, and quits once the form is completed or aborted
+type previewModel struct {
+	form     *templateForm
+	template Template
+	width    int
+}
+
+// paneWidths returns the width of the form and of the preview
+func (m previewModel) paneWidths() (int, int) {
+	formWidth := m.width / 2
+	// The border and padding of the preview take four columns
+	return formWidth, m.width - formWidth - 4
+}
+
+func (m previewModel) Init() tea.Cmd {
+	m.form.form.WithWidth(m.width / 2)
+	return m.form.form.Init()
+}
+
+func (m previewModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if size, ok := msg.(tea.WindowSizeMsg); ok {
+		m.width = size.Width
+		formWidth, _ := m.paneWidths()
+		m.form.form.WithWidth(formWidth)
+	}
+
+	model, cmd := m.form.form.Update(msg)
+	m.form.form = model.(*huh.Form)
+
+	if m.form.form.State != huh.StateNormal {
+		return m, tea.Quit
+	}
+
+	return m, cmd
+}
+
+func (m previewModel) View() string {
+	if m.form.form.State != huh.StateNormal {
+		return ""
+	}
+
+	formWidth, previewWidth := m.paneWidths()
+	left := lipgloss.NewStyle().Width(formWidth).Render(m.form.form.View())
+	right := renderPreview(previewText(m.template, m.form.values()), previewWidth)
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, left, right)
+}
+
+// reviewMessage asks whether to use text, letting it be edited first. It
+// returns huh.ErrUserAborted when the message is cancelled.
+func reviewMessage(text string) (string, error) {
+	for {
+		description := strings.TrimRight(text, "\n")
+		if warnings, _ := subjectWarnings(text); len(warnings) > 0 {
+			description += "\n\n! " + strings.Join(warnings, "\n! ")
+		}
+
+		action := reviewConfirm
+		form := huh.NewForm(huh.NewGroup(
+			huh.NewSelect[string]().
+				Title("Use this message?").
+				Description(description).
+				Options(
+					huh.NewOption("Confirm", reviewConfirm),
+					huh.NewOption("Edit", reviewEdit),
+					huh.NewOption("Cancel", reviewCancel),
+				).
+				Value(&action),
+		))
+
+		if err := runForm(form); err != nil {
+			return "", err
+		}
+
+		switch action {
+		case reviewConfirm:
+			return text, nil
+		case reviewCancel:
+			return "", huh.ErrUserAborted
+		}
+
+		edit := huh.NewForm(huh.NewGroup(
+			huh.NewText().
+				Title("Commit message").
+				CharLimit(0).
+				Lines(10).
+				Value(&text),
+		))
+
+		if err := runForm(edit); err != nil {
+			return "", err
+		}
+	}
+}
+
+// PopulateWithPreview works like PopulateFromForm, showing a live preview of
+// the message next to the form. The rendered message can then be confirmed,
+// edited or cancelled.
+func PopulateWithPreview(template Template, values map[string]string) (string, error) {
+	f, err := newTemplateForm(template, values)
+	if err != nil {
+		return "", err
+	}
+
+	if f.form != nil {
+		model := previewModel{form: f, template: template, width: defaultWidth}
+
+		_, err = tea.NewProgram(model, tea.WithOutput(output)).Run()
+		if err != nil {
+			return "", fmt.Errorf("error running form: %v", err)
+		}
+
+		if f.form.State == huh.StateAborted {
+			return "", fmt.Errorf("error running form: %v", huh.ErrUserAborted)
+		}
+	}
+
+	text, err := PopulateTemplate(template, f.values())
+	if err != nil {
+		return "", err
+	}
+
+	text, err = reviewMessage(text)
+	if err != nil {
+		return "", fmt.Errorf("error running form: %v", err)
+	}
+
+	return text, nil
+}
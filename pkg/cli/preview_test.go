@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreviewText(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "[%{type}] %{title}\n\n%{?body}%{body}%{/body}\n",
+		Variables: []Variable{
+			{Name: "type", Default: "feat"},
+			{Name: "title"},
+			{Name: "body"},
+		},
+	}
+
+	testCases := []struct {
+		description string
+		values      map[string]string
+		want        string
+	}{
+		{
+			description: "should keep placeholders without a value",
+			values:      map[string]string{"type": "fix", "title": "", "body": ""},
+			want:        "[fix] %{title}\n",
+		},
+		{
+			description: "should render values as they are typed",
+			values:      map[string]string{"type": "fix", "title": "Handle errors", "body": "Details"},
+			want:        "[fix] Handle errors\n\nDetails\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			if got := previewText(template, tc.values); got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSubjectWarnings(t *testing.T) {
+	testCases := []struct {
+		description string
+		text        string
+		warnings    int
+		serious     bool
+	}{
+		{description: "should accept a short subject", text: "Fix login\n\nBody\n"},
+		{description: "should warn about a long subject", text: strings.Repeat("a", 60) + "\n", warnings: 1},
+		{description: "should flag a subject that gets truncated", text: strings.Repeat("a", 80) + "\n", warnings: 1, serious: true},
+		{description: "should warn about a missing blank line", text: "Fix login\nBody\n", warnings: 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			warnings, serious := subjectWarnings(tc.text)
+			if len(warnings) != tc.warnings {
+				t.Errorf("expected %d warning(s), got %v", tc.warnings, warnings)
+			}
+			if serious != tc.serious {
+				t.Errorf("expected serious to be %v, got %v", tc.serious, serious)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Report formats understood by FormatReport
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// sarifRuleID is the id of the SARIF rule every validation error is reported
+// under
+const sarifRuleID = "invalid-template"
+
+// sarifLog is the subset of a SARIF 2.1.0 log written by FormatReport
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+// sarifReport builds a SARIF log from validation errors
+func sarifReport(errs ValidationErrors) sarifLog {
+	results := []sarifResult{}
+
+	for _, err := range errs {
+		// File and line are given by the location
+		message := err
+		message.File = ""
+		message.Line = 0
+
+		result := sarifResult{
+			RuleID:  sarifRuleID,
+			Level:   "error",
+			Message: sarifMessage{Text: message.Error()},
+		}
+
+		if err.File != "" {
+			location := sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(err.File)},
+				},
+			}
+			if err.Line > 0 {
+				location.PhysicalLocation.Region = &sarifRegion{StartLine: err.Line}
+			}
+			result.Locations = []sarifLocation{location}
+		}
+
+		results = append(results, result)
+	}
+
+	return sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           "ComTemplate",
+				InformationURI: "https://github.com/iamlucasvieira/ComTemplate",
+				Rules: []sarifRule{{
+					ID:               sarifRuleID,
+					ShortDescription: sarifMessage{Text: "Template file is invalid"},
+				}},
+			}},
+			Results: results,
+		}},
+	}
+}
+
+// FormatReport formats validation errors as plain text, JSON or SARIF
+func FormatReport(errs ValidationErrors, format string) (string, error) {
+	switch format {
+	case FormatText:
+		var builder strings.Builder
+		for _, err := range errs {
+			fmt.Fprintln(&builder, err.Error())
+		}
+		return builder.String(), nil
+	case FormatJSON:
+		if errs == nil {
+			errs = ValidationErrors{}
+		}
+		data, err := json.MarshalIndent(errs, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error formatting report: %v", err)
+		}
+		return string(data) + "\n", nil
+	case FormatSARIF:
+		data, err := json.MarshalIndent(sarifReport(errs), "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("error formatting report: %v", err)
+		}
+		return string(data) + "\n", nil
+	default:
+		return "", fmt.Errorf("unknown format '%s': expected text, json or sarif", format)
+	}
+}
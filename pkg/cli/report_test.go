@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+var reportErrors = ValidationErrors{
+	{File: "comtemplate.yml", Template: 1, Variable: 0, Field: "type", Line: 10, Message: "variable type has invalid type weird"},
+	{File: "comtemplate.yml", Template: -1, Variable: -1, Message: "error parsing yaml"},
+}
+
+func TestFormatReportText(t *testing.T) {
+	report, err := FormatReport(reportErrors, FormatText)
+	if err != nil {
+		t.Fatalf("error formatting report: %v", err)
+	}
+
+	want := "comtemplate.yml: Template 1 - Variable 0 (line 10): variable type has invalid type weird\ncomtemplate.yml: error parsing yaml\n"
+	if report != want {
+		t.Errorf("expected report to be '%s', got '%s'", want, report)
+	}
+}
+
+func TestFormatReportJSON(t *testing.T) {
+	report, err := FormatReport(reportErrors, FormatJSON)
+	if err != nil {
+		t.Fatalf("error formatting report: %v", err)
+	}
+
+	var got ValidationErrors
+	err = json.Unmarshal([]byte(report), &got)
+	if err != nil {
+		t.Fatalf("error decoding report: %v", err)
+	}
+
+	if len(got) != 2 || got[0] != reportErrors[0] {
+		t.Errorf("expected report to hold the errors, got %v", got)
+	}
+
+	t.Run("should write an empty list without errors", func(t *testing.T) {
+		report, _ := FormatReport(nil, FormatJSON)
+		if strings.TrimSpace(report) != "[]" {
+			t.Errorf("expected '[]', got '%s'", report)
+		}
+	})
+}
+
+func TestFormatReportSARIF(t *testing.T) {
+	report, err := FormatReport(reportErrors, FormatSARIF)
+	if err != nil {
+		t.Fatalf("error formatting report: %v", err)
+	}
+
+	var got sarifLog
+	err = json.Unmarshal([]byte(report), &got)
+	if err != nil {
+		t.Fatalf("error decoding report: %v", err)
+	}
+
+	results := got.Runs[0].Results
+	if len(results) != 2 {
+		t.Fatalf("expected two results, got %d", len(results))
+	}
+
+	t.Run("should locate results", func(t *testing.T) {
+		location := results[0].Locations[0].PhysicalLocation
+		if location.ArtifactLocation.URI != "comtemplate.yml" || location.Region.StartLine != 10 {
+			t.Errorf("expected result at comtemplate.yml:10, got %+v", location)
+		}
+	})
+
+	t.Run("should leave the location out of the message", func(t *testing.T) {
+		want := "Template 1 - Variable 0: variable type has invalid type weird"
+		if results[0].Message.Text != want {
+			t.Errorf("expected message '%s', got '%s'", want, results[0].Message.Text)
+		}
+	})
+
+	t.Run("should omit the region without line", func(t *testing.T) {
+		if results[1].Locations[0].PhysicalLocation.Region != nil {
+			t.Errorf("expected no region")
+		}
+	})
+}
+
+func TestFormatReportUnknown(t *testing.T) {
+	if _, err := FormatReport(nil, "xml"); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestValidate(t *testing.T) {
+	f, err := os.CreateTemp("", "test")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write([]byte("- name: a\n  text: [\n"))
+	if err != nil {
+		t.Fatalf("error writing to temp file: %v", err)
+	}
+	f.Close()
+
+	errs := Validate(f.Name())
+	if len(errs) != 1 {
+		t.Fatalf("expected one error, got %v", errs)
+	}
+
+	if errs[0].Template != -1 || errs[0].Line == 0 || errs[0].File != f.Name() {
+		t.Errorf("expected a located file error, got %+v", errs[0])
+	}
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// settingsName is the name of the settings file in the user and system
+	// config directories
+	settingsName = "settings.yml"
+	// AllowCommandsEnv is the environment variable overriding the
+	// allow_commands setting
+	AllowCommandsEnv = "COMTEMPLATE_ALLOW_COMMANDS"
+)
+
+// Settings are preferences read from settings.yml in the system and user
+// config directories. They are not read from repositories, so that a
+// repository cannot change them.
+type Settings struct {
+	// AllowCommands lets templates run commands, such as options_command.
+	// It is off by default, since any repository can ship a template file.
+	AllowCommands bool `yaml:"allow_commands"`
+}
+
+// DefaultSettings returns the settings used when no settings file exists
+func DefaultSettings() Settings {
+	return Settings{AllowCommands: false}
+}
+
+// ReadSettings reads the system settings, then the user settings, which
+// override them, and finally the environment
+func ReadSettings() (Settings, error) {
+	settings := DefaultSettings()
+
+	for _, dir := range []string{systemConfigDir, userConfigDir()} {
+		if dir == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, settingsName)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return settings, fmt.Errorf("error reading settings: %v", err)
+		}
+
+		// Settings missing from the file keep their value
+		if err := yaml.Unmarshal(data, &settings); err != nil {
+			return settings, fmt.Errorf("error reading settings %s: %v", path, err)
+		}
+	}
+
+	if value, ok := os.LookupEnv(AllowCommandsEnv); ok {
+		allow, err := parseBool(value)
+		if err != nil {
+			return settings, fmt.Errorf("error reading %s: %v", AllowCommandsEnv, err)
+		}
+		settings.AllowCommands = allow
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestReadSettings(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalSystemDir := systemConfigDir
+	systemConfigDir = filepath.Join(tempDir, "etc")
+	t.Cleanup(func() { systemConfigDir = originalSystemDir })
+
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tempDir, "config"))
+
+	t.Run("should not allow commands by default", func(t *testing.T) {
+		settings, err := ReadSettings()
+		if err != nil {
+			t.Fatalf("error reading settings: %v", err)
+		}
+		if settings.AllowCommands {
+			t.Errorf("expected commands not to be allowed")
+		}
+	})
+
+	t.Run("should allow commands from the environment", func(t *testing.T) {
+		t.Setenv(AllowCommandsEnv, "1")
+
+		settings, err := ReadSettings()
+		if err != nil {
+			t.Fatalf("error reading settings: %v", err)
+		}
+		if !settings.AllowCommands {
+			t.Errorf("expected %s to allow commands", AllowCommandsEnv)
+		}
+	})
+
+	t.Run("should read the user settings over the system ones", func(t *testing.T) {
+		writeFile(t, filepath.Join(systemConfigDir, settingsName), "allow_commands: false\n")
+		writeFile(t, filepath.Join(userConfigDir(), settingsName), "allow_commands: true\n")
+
+		settings, err := ReadSettings()
+		if err != nil {
+			t.Fatalf("error reading settings: %v", err)
+		}
+		if !settings.AllowCommands {
+			t.Errorf("expected user settings to allow commands")
+		}
+	})
+
+	t.Run("should read the environment over the files", func(t *testing.T) {
+		t.Setenv(AllowCommandsEnv, "no")
+
+		settings, err := ReadSettings()
+		if err != nil {
+			t.Fatalf("error reading settings: %v", err)
+		}
+		if settings.AllowCommands {
+			t.Errorf("expected %s to disallow commands", AllowCommandsEnv)
+		}
+	})
+
+	t.Run("should reject invalid settings", func(t *testing.T) {
+		writeFile(t, filepath.Join(userConfigDir(), settingsName), "allow_commands: [\n")
+
+		if _, err := ReadSettings(); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
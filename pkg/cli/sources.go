@@ -0,0 +1,452 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// sourceBranch is the source of variables taking their value from the
+// current git branch
+const sourceBranch = "branch"
+
+// sourceValue returns the value of a variable with the branch source, taken
+// from the first capture group of its regex, or from the whole match when
+// the regex has no group. It returns false when the branch does not match.
+func (v Variable) sourceValue(branch string) (string, bool) {
+	if v.Regex == "" {
+		return branch, branch != ""
+	}
+
+	re, err := regexp.Compile(v.Regex)
+	if err != nil {
+		return "", false
+	}
+
+	match := re.FindStringSubmatch(branch)
+	if match == nil {
+		return "", false
+	}
+
+	if len(match) > 1 {
+		return match[1], match[1] != ""
+	}
+	return match[0], match[0] != ""
+}
+
+// ApplySources returns copies of template and values where variables with a
+// source and without a value take their value from the source. Variables set
+// to prefill get it as default instead, so that it is shown in the form.
+// When the source gives no valid value, the variable is asked for as usual.
+//
+// Variables with an options command or file get the options loaded from them,
+// and variables with options from the staged paths get the scopes of the
+// staged files as first options, and the most common one as default. Options
+// commands are only run when settings allow commands.
+func ApplySources(template Template, values map[string]string, settings Settings) (Template, map[string]string, error) {
+	filled := make(map[string]string)
+	for name, value := range values {
+		filled[name] = value
+	}
+
+	variables := make([]Variable, len(template.Variables))
+	copy(variables, template.Variables)
+	template.Variables = variables
+
+	// The branch and the staged files are read once, and only when a
+	// variable needs them
+	var branch *string
+	var staged []string
+	stagedRead := false
+
+	for i, variable := range template.Variables {
+		if variable.dynamicOptions() {
+			loaded, err := variable.loadOptions(filepath.Dir(template.Source), settings)
+			if err != nil {
+				return template, nil, fmt.Errorf("options of %s: %v", variable.Name, err)
+			}
+			template.Variables[i].Options = loaded
+			variable = template.Variables[i]
+
+			// Validation leaves the default until the options are known
+			if variable.Default != "" {
+				value, err := variable.normalize(variable.Default)
+				if err == nil {
+					err = variable.check(value)
+				}
+				if err != nil {
+					return template, nil, fmt.Errorf("default of %s: %v", variable.Name, err)
+				}
+			}
+		}
+
+		if variable.OptionsFrom == sourceStagedPaths {
+			if !stagedRead {
+				staged, _ = StagedPaths()
+				stagedRead = true
+			}
+			template.Variables[i] = variable.withSuggestions(variable.suggestScopes(staged))
+			variable = template.Variables[i]
+		}
+
+		if variable.Source != sourceBranch {
+			continue
+		}
+		if _, ok := filled[variable.Name]; ok {
+			continue
+		}
+
+		if branch == nil {
+			name, _ := CurrentBranch()
+			branch = &name
+		}
+
+		value, ok := variable.sourceValue(*branch)
+		if !ok {
+			continue
+		}
+		if normalized, err := variable.normalize(value); err != nil || variable.check(normalized) != nil {
+			continue
+		}
+
+		if variable.Prefill {
+			template.Variables[i].Default = value
+		} else {
+			filled[variable.Name] = value
+		}
+	}
+
+	return template, filled, nil
+}
+
+// sourceStagedPaths is the source of options computed from the staged files
+const sourceStagedPaths = "staged_paths"
+
+// pathRule maps the files matching a glob to a scope
+type pathRule struct {
+	glob  string
+	scope string
+}
+
+// parsePathRule parses a rule written as "glob -> scope"
+func parsePathRule(rule string) (pathRule, error) {
+	glob, scope, ok := strings.Cut(rule, "->")
+	glob, scope = strings.TrimSpace(glob), strings.TrimSpace(scope)
+
+	if !ok || glob == "" || scope == "" {
+		return pathRule{}, fmt.Errorf("path rule '%s' is not written as 'glob -> scope'", rule)
+	}
+
+	for _, part := range strings.Split(glob, "/") {
+		if _, err := path.Match(part, ""); err != nil {
+			return pathRule{}, fmt.Errorf("path rule '%s' has invalid glob: %v", rule, err)
+		}
+	}
+
+	return pathRule{glob: glob, scope: scope}, nil
+}
+
+// pathRules returns the valid path rules of the variable
+func (v Variable) pathRules() []pathRule {
+	var rules []pathRule
+	for _, rule := range v.Paths {
+		if r, err := parsePathRule(rule); err == nil {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// allOptions returns the options of the variable, followed by the scopes of
+// its path rules
+func (v Variable) allOptions() []string {
+	options := slices.Clone(v.Options)
+	for _, rule := range v.pathRules() {
+		if !slices.Contains(options, rule.scope) {
+			options = append(options, rule.scope)
+		}
+	}
+	return options
+}
+
+// matchGlob reports whether a slash separated path matches glob, where **
+// matches any number of directories and other parts are matched with
+// path.Match
+func matchGlob(glob, name string) bool {
+	return matchParts(strings.Split(glob, "/"), strings.Split(name, "/"))
+}
+
+func matchParts(glob, name []string) bool {
+	if len(glob) == 0 {
+		return len(name) == 0
+	}
+
+	if glob[0] == "**" {
+		for i := 0; i <= len(name); i++ {
+			if matchParts(glob[1:], name[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(name) == 0 {
+		return false
+	}
+
+	ok, err := path.Match(glob[0], name[0])
+	return err == nil && ok && matchParts(glob[1:], name[1:])
+}
+
+// suggestScopes returns the scopes of the staged files, the most common first.
+// Each file counts for the first rule it matches.
+func (v Variable) suggestScopes(staged []string) []string {
+	rules := v.pathRules()
+	counts := make(map[string]int)
+	var scopes []string
+
+	for _, file := range staged {
+		for _, rule := range rules {
+			if !matchGlob(rule.glob, file) {
+				continue
+			}
+			if counts[rule.scope] == 0 {
+				scopes = append(scopes, rule.scope)
+			}
+			counts[rule.scope]++
+			break
+		}
+	}
+
+	// Scopes with the same count keep the order they were found in
+	sort.SliceStable(scopes, func(i, j int) bool { return counts[scopes[i]] > counts[scopes[j]] })
+
+	return scopes
+}
+
+// withSuggestions returns a copy of the variable with the suggested scopes as
+// first options and as default, unless it has one
+func (v Variable) withSuggestions(suggestions []string) Variable {
+	options := slices.Clone(suggestions)
+	for _, option := range v.allOptions() {
+		if !slices.Contains(options, option) {
+			options = append(options, option)
+		}
+	}
+	v.Options = options
+
+	if v.Default == "" && len(suggestions) > 0 {
+		if v.Type == "multiselect" {
+			v.Default = strings.Join(suggestions, listSeparator)
+		} else {
+			v.Default = suggestions[0]
+		}
+	}
+
+	return v
+}
+
+// enableCommandsHint tells how to let templates run commands
+var enableCommandsHint = fmt.Sprintf("set allow_commands: true in %s of the user config directory, or %s=1, to trust the templates", settingsName, AllowCommandsEnv)
+
+// SkippedCommands returns the names of the variables of template whose
+// options command is not run with settings
+func SkippedCommands(template Template, settings Settings) []string {
+	if settings.AllowCommands {
+		return nil
+	}
+
+	var names []string
+	for _, variable := range template.Variables {
+		if variable.OptionsCommand != "" {
+			names = append(names, variable.Name)
+		}
+	}
+	return names
+}
+
+// SkippedCommandsWarning describes the options commands skipped for the
+// variables with the given names
+func SkippedCommandsWarning(names []string) string {
+	return fmt.Sprintf("Options commands of %s were not run: %s.", strings.Join(names, ", "), enableCommandsHint)
+}
+
+// defaultOptionsTimeout is how long options commands can run by default
+const defaultOptionsTimeout = 5 * time.Second
+
+// dynamicOptions reports whether options of the variable are loaded from a
+// command or a file
+func (v Variable) dynamicOptions() bool {
+	return v.OptionsCommand != "" || v.OptionsFile != ""
+}
+
+// duration parses an optional duration setting of the variable
+func duration(s string, fallback time.Duration) time.Duration {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d
+	}
+	return fallback
+}
+
+// optionLines returns the options listed in s, one per line. Blank lines are
+// skipped, and so are lines starting with # when comments is true.
+func optionLines(s string, comments bool) []string {
+	var options []string
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || (comments && strings.HasPrefix(line, "#")) {
+			continue
+		}
+		if !slices.Contains(options, line) {
+			options = append(options, line)
+		}
+	}
+	return options
+}
+
+// optionsCachePath returns the file caching the output of an options command
+// run in dir
+func optionsCachePath(dir, command string) string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+
+	sum := sha256.Sum256([]byte(dir + "\x00" + command))
+	return filepath.Join(cacheDir, "comtemplate", "options", hex.EncodeToString(sum[:])+".txt")
+}
+
+// runOptionsCommand runs command with sh in the current directory and returns
+// its output. The command is stopped after timeout.
+func runOptionsCommand(command string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	// Children of sh may keep the output open after it is killed
+	cmd.WaitDelay = time.Second
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("command '%s' timed out after %s", command, timeout)
+	}
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("command '%s' failed: %s", command, message)
+	}
+
+	return stdout.String(), nil
+}
+
+// commandOptions returns the options printed by the options command of the
+// variable, reusing its cached output while it is more recent than
+// OptionsCache
+func (v Variable) commandOptions() ([]string, error) {
+	cache := duration(v.OptionsCache, 0)
+
+	cwd, _ := os.Getwd()
+	cachePath := optionsCachePath(cwd, v.OptionsCommand)
+
+	if cache > 0 {
+		if info, err := os.Stat(cachePath); err == nil && time.Since(info.ModTime()) < cache {
+			if data, err := os.ReadFile(cachePath); err == nil {
+				return optionLines(string(data), false), nil
+			}
+		}
+	}
+
+	output, err := runOptionsCommand(v.OptionsCommand, duration(v.OptionsTimeout, defaultOptionsTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	// Failing to cache only makes the next run slower
+	if cache > 0 {
+		if err := os.MkdirAll(filepath.Dir(cachePath), 0755); err == nil {
+			_ = os.WriteFile(cachePath, []byte(output), 0644)
+		}
+	}
+
+	return optionLines(output, false), nil
+}
+
+// fileOptions returns the options listed in the options file of the variable,
+// relative to dir
+func (v Variable) fileOptions(dir string) ([]string, error) {
+	path := v.OptionsFile
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading options file: %v", err)
+	}
+
+	return optionLines(string(data), true), nil
+}
+
+// loadOptions returns the static options of the variable followed by the ones
+// of its options file, relative to dir, and of its options command. The
+// command is skipped when settings do not allow commands. It fails when no
+// option is left, since nothing could be chosen.
+func (v Variable) loadOptions(dir string, settings Settings) ([]string, error) {
+	options := slices.Clone(v.Options)
+
+	add := func(loaded []string) {
+		for _, option := range loaded {
+			if !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+	}
+
+	if v.OptionsFile != "" {
+		loaded, err := v.fileOptions(dir)
+		if err != nil {
+			return nil, err
+		}
+		add(loaded)
+	}
+
+	if v.OptionsCommand != "" {
+		if !settings.AllowCommands {
+			if len(options) == 0 {
+				return nil, fmt.Errorf("options come from a command, but commands are disabled: %s", enableCommandsHint)
+			}
+			return options, nil
+		}
+
+		loaded, err := v.commandOptions()
+		if err != nil {
+			return nil, err
+		}
+		add(loaded)
+	}
+
+	if len(options) == 0 {
+		return nil, fmt.Errorf("the options file and command gave no options")
+	}
+
+	return options, nil
+}
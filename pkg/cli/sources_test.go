@@ -0,0 +1,314 @@
+package cli
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSourceValue(t *testing.T) {
+	testCases := []struct {
+		description string
+		regex       string
+		branch      string
+		want        string
+		ok          bool
+	}{
+		{description: "should use the capture group", regex: `([A-Z]+-[0-9]+)`, branch: "feature/PROJ-1234-short-desc", want: "PROJ-1234", ok: true},
+		{description: "should use the first capture group", regex: `^(\w+)/(\w+)`, branch: "fix/login", want: "fix", ok: true},
+		{description: "should use the whole match without groups", regex: `[0-9]+`, branch: "issue-42", want: "42", ok: true},
+		{description: "should use the whole branch without regex", branch: "main", want: "main", ok: true},
+		{description: "should fail when the branch does not match", regex: `([A-Z]+-[0-9]+)`, branch: "main"},
+		{description: "should fail without branch", regex: `(.*)`, branch: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			v := Variable{Name: "ticket", Source: sourceBranch, Regex: tc.regex}
+			got, ok := v.sourceValue(tc.branch)
+			if ok != tc.ok || got != tc.want {
+				t.Errorf("expected (%q, %v), got (%q, %v)", tc.want, tc.ok, got, ok)
+			}
+		})
+	}
+}
+
+func TestApplySources(t *testing.T) {
+	initRepo(t)
+
+	if _, err := runGit(nil, "checkout", "--quiet", "-b", "feature/PROJ-1234-short-desc"); err != nil {
+		t.Fatalf("error creating branch: %v", err)
+	}
+
+	template := Template{
+		Name: "Test",
+		Text: "%{ticket}: %{title} %{type} %{scope}",
+		Variables: []Variable{
+			{Name: "ticket", Source: sourceBranch, Regex: `([A-Z]+-[0-9]+)`},
+			{Name: "title"},
+			{Name: "type", Source: sourceBranch, Regex: `^(\w+)/`, Prefill: true},
+			{Name: "scope", Source: sourceBranch, Regex: `^release/(.*)`},
+		},
+	}
+
+	t.Run("should fill values from the branch", func(t *testing.T) {
+		got, values, _ := ApplySources(template, nil, DefaultSettings())
+
+		if values["ticket"] != "PROJ-1234" {
+			t.Errorf("expected ticket to be filled, got %v", values)
+		}
+		if _, ok := values["type"]; ok {
+			t.Errorf("expected prefilled type to be asked for, got %v", values)
+		}
+		if got.Variables[2].Default != "feature" {
+			t.Errorf("expected type default to be feature, got %q", got.Variables[2].Default)
+		}
+		if template.Variables[2].Default != "" {
+			t.Errorf("expected template to be left unchanged")
+		}
+		if _, ok := values["scope"]; ok {
+			t.Errorf("expected scope to be asked for when the branch does not match, got %v", values)
+		}
+	})
+
+	t.Run("should keep given values", func(t *testing.T) {
+		_, values, _ := ApplySources(template, map[string]string{"ticket": "OTHER-1"}, DefaultSettings())
+
+		if values["ticket"] != "OTHER-1" {
+			t.Errorf("expected given ticket to be kept, got %v", values)
+		}
+	})
+
+	t.Run("should skip values failing the checks", func(t *testing.T) {
+		strict := template
+		strict.Variables = []Variable{{Name: "ticket", Source: sourceBranch, Regex: `([A-Z]+-[0-9]+)`, MaxLength: 4}}
+
+		_, values, _ := ApplySources(strict, nil, DefaultSettings())
+		if _, ok := values["ticket"]; ok {
+			t.Errorf("expected ticket to be asked for, got %v", values)
+		}
+	})
+}
+
+func TestMatchGlob(t *testing.T) {
+	testCases := []struct {
+		glob  string
+		name  string
+		match bool
+	}{
+		{glob: "services/billing/**", name: "services/billing/api/main.go", match: true},
+		{glob: "services/billing/**", name: "services/billing", match: true},
+		{glob: "services/billing/**", name: "services/shipping/main.go", match: false},
+		{glob: "**/*.md", name: "README.md", match: true},
+		{glob: "**/*.md", name: "docs/guide/setup.md", match: true},
+		{glob: "cmd/*.go", name: "cmd/root.go", match: true},
+		{glob: "cmd/*.go", name: "cmd/sub/root.go", match: false},
+	}
+
+	for _, tc := range testCases {
+		if got := matchGlob(tc.glob, tc.name); got != tc.match {
+			t.Errorf("%s on %s: expected %v, got %v", tc.glob, tc.name, tc.match, got)
+		}
+	}
+}
+
+func TestSuggestScopes(t *testing.T) {
+	v := Variable{
+		Name:        "scope",
+		Type:        "select",
+		OptionsFrom: sourceStagedPaths,
+		Paths: []string{
+			"services/billing/** -> billing",
+			"services/** -> services",
+			"**/*.md -> docs",
+		},
+	}
+
+	staged := []string{
+		"README.md",
+		"services/billing/invoice.go",
+		"services/billing/tax.go",
+		"services/shipping/label.go",
+	}
+
+	got := v.suggestScopes(staged)
+	want := []string{"billing", "docs", "services"}
+	if !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	v = v.withSuggestions(got)
+	if v.Default != "billing" {
+		t.Errorf("expected default to be billing, got %s", v.Default)
+	}
+	if !slices.Equal(v.Options, []string{"billing", "docs", "services"}) {
+		t.Errorf("expected suggestions first in options, got %v", v.Options)
+	}
+}
+
+func TestApplySourcesStagedPaths(t *testing.T) {
+	initRepo(t)
+
+	for _, name := range []string{"api/users.go", "api/orders.go", "web/index.html"} {
+		writeFile(t, name, "")
+	}
+	if _, err := runGit(nil, "add", "."); err != nil {
+		t.Fatalf("error staging files: %v", err)
+	}
+
+	template := Template{
+		Name: "Test",
+		Text: "%{scope}: %{title}",
+		Variables: []Variable{
+			{Name: "scope", Type: "select", Options: []string{"deps"}, OptionsFrom: sourceStagedPaths, Paths: []string{"web/** -> web", "api/** -> api"}},
+			{Name: "title"},
+		},
+	}
+
+	got, _, err := ApplySources(template, nil, DefaultSettings())
+	if err != nil {
+		t.Fatalf("error applying sources: %v", err)
+	}
+
+	scope := got.Variables[0]
+	if scope.Default != "api" {
+		t.Errorf("expected default to be api, got %s", scope.Default)
+	}
+	if want := []string{"api", "web", "deps"}; !slices.Equal(scope.Options, want) {
+		t.Errorf("expected options %v, got %v", want, scope.Options)
+	}
+}
+
+func TestApplySourcesDynamicOptions(t *testing.T) {
+	dir := initRepo(t)
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(dir, ".cache"))
+
+	writeFile(t, "teams.txt", "# Teams\nplatform\n\nmobile\n")
+
+	trusted := Settings{AllowCommands: true}
+
+	template := Template{
+		Name:   "Test",
+		Source: filepath.Join(dir, "comtemplate.yml"),
+		Text:   "%{team} %{component}",
+		Variables: []Variable{
+			{Name: "team", Type: "select", Options: []string{"core"}, OptionsFile: "teams.txt"},
+			{Name: "component", Type: "multiselect", OptionsCommand: "printf 'api\\nweb\\n'", OptionsCache: "1h"},
+		},
+	}
+
+	t.Run("should load options from the file and the command", func(t *testing.T) {
+		got, _, err := ApplySources(template, nil, trusted)
+		if err != nil {
+			t.Fatalf("error applying sources: %v", err)
+		}
+
+		if want := []string{"core", "platform", "mobile"}; !slices.Equal(got.Variables[0].Options, want) {
+			t.Errorf("expected options %v, got %v", want, got.Variables[0].Options)
+		}
+		if want := []string{"api", "web"}; !slices.Equal(got.Variables[1].Options, want) {
+			t.Errorf("expected options %v, got %v", want, got.Variables[1].Options)
+		}
+	})
+
+	t.Run("should reuse the cached output", func(t *testing.T) {
+		cached := template
+		cached.Variables = []Variable{{Name: "component", Type: "multiselect", OptionsCommand: "printf 'api\\nweb\\n'", OptionsCache: "1h"}}
+
+		writeFile(t, optionsCachePath(dir, cached.Variables[0].OptionsCommand), "cli\n")
+
+		got, _, err := ApplySources(cached, nil, trusted)
+		if err != nil {
+			t.Fatalf("error applying sources: %v", err)
+		}
+		if want := []string{"cli"}; !slices.Equal(got.Variables[0].Options, want) {
+			t.Errorf("expected options %v, got %v", want, got.Variables[0].Options)
+		}
+	})
+
+	t.Run("should report failing commands", func(t *testing.T) {
+		failing := template
+		failing.Variables = []Variable{{Name: "component", Type: "select", OptionsCommand: "echo oops >&2; exit 3"}}
+
+		_, _, err := ApplySources(failing, nil, trusted)
+		if err == nil || !strings.Contains(err.Error(), "oops") {
+			t.Errorf("expected error with the command output, got %v", err)
+		}
+	})
+
+	t.Run("should stop slow commands", func(t *testing.T) {
+		slow := template
+		slow.Variables = []Variable{{Name: "component", Type: "select", OptionsCommand: "sleep 5", OptionsTimeout: "100ms"}}
+
+		_, _, err := ApplySources(slow, nil, trusted)
+		if err == nil || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("expected timeout error, got %v", err)
+		}
+	})
+
+	t.Run("should check the default against the loaded options", func(t *testing.T) {
+		withDefault := template
+		withDefault.Variables = []Variable{{Name: "team", Type: "select", OptionsFile: "teams.txt", Default: "bogus"}}
+
+		_, _, err := ApplySources(withDefault, nil, trusted)
+		if err == nil || !strings.Contains(err.Error(), "default of team") {
+			t.Errorf("expected error about the default, got %v", err)
+		}
+
+		withDefault.Variables[0].Default = "mobile"
+		got, _, err := ApplySources(withDefault, nil, trusted)
+		if err != nil {
+			t.Fatalf("error applying sources: %v", err)
+		}
+		if got.Variables[0].Default != "mobile" {
+			t.Errorf("expected default to be kept, got %q", got.Variables[0].Default)
+		}
+	})
+
+	t.Run("should fail without any option", func(t *testing.T) {
+		writeFile(t, "empty.txt", "# No teams yet\n")
+
+		empty := template
+		empty.Variables = []Variable{
+			{Name: "team", Type: "select", OptionsFile: "empty.txt"},
+		}
+		if _, _, err := ApplySources(empty, nil, trusted); err == nil {
+			t.Errorf("expected error for an empty options file, got nil")
+		}
+
+		empty.Variables = []Variable{{Name: "component", Type: "select", OptionsCommand: "true"}}
+		if _, _, err := ApplySources(empty, nil, trusted); err == nil {
+			t.Errorf("expected error for a command printing nothing, got nil")
+		}
+	})
+
+	t.Run("should not run commands when disallowed", func(t *testing.T) {
+		settings := DefaultSettings()
+
+		if got := SkippedCommands(template, settings); !slices.Equal(got, []string{"component"}) {
+			t.Errorf("expected the command of component to be skipped, got %v", got)
+		}
+		if got := SkippedCommands(template, trusted); len(got) != 0 {
+			t.Errorf("expected no command to be skipped when trusted, got %v", got)
+		}
+
+		withStatic := template
+		withStatic.Variables = []Variable{{Name: "component", Type: "select", Options: []string{"core"}, OptionsCommand: "exit 1"}}
+
+		got, _, err := ApplySources(withStatic, nil, settings)
+		if err != nil {
+			t.Fatalf("error applying sources: %v", err)
+		}
+		if want := []string{"core"}; !slices.Equal(got.Variables[0].Options, want) {
+			t.Errorf("expected static options %v, got %v", want, got.Variables[0].Options)
+		}
+
+		withoutStatic := template
+		withoutStatic.Variables = []Variable{{Name: "component", Type: "select", OptionsCommand: "exit 1"}}
+
+		if _, _, err := ApplySources(withoutStatic, nil, settings); err == nil {
+			t.Errorf("expected error without any option, got nil")
+		}
+	})
+}
@@ -2,8 +2,11 @@ package cli
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 const (
@@ -16,6 +19,8 @@ var (
 	subtle    = lipgloss.AdaptiveColor{Light: "#D9DCCF", Dark: "#383838"}
 	highlight = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
 	special   = lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}
+	muted     = lipgloss.AdaptiveColor{Light: "#9B9B9B", Dark: "#5C5C5C"}
+	failure   = lipgloss.AdaptiveColor{Light: "#E0245E", Dark: "#FF5F87"}
 
 	Shell = lipgloss.NewStyle().
 		MarginLeft(marginLeft).
@@ -53,8 +58,26 @@ var (
 	TextHighlight = lipgloss.NewStyle().
 			Foreground(highlight).
 			Render
+
+	TextSubtle = lipgloss.NewStyle().
+			Foreground(muted).
+			Render
+
+	TextError = lipgloss.NewStyle().
+			Foreground(failure).
+			Render
 )
 
+// output is where messages are printed and forms are drawn
+var output io.Writer = os.Stdout
+
+// SetOutput sets where messages are printed and forms are drawn, keeping
+// standard output free for the commit message
+func SetOutput(f *os.File) {
+	output = f
+	lipgloss.DefaultRenderer().SetOutput(termenv.NewOutput(f))
+}
+
 // RenderList renders a list of strings
 func RenderList(title string, items []string) string {
 	// Transform []string into []ListItemTick
@@ -77,11 +100,11 @@ func RenderList(title string, items []string) string {
 // Write prints a list of strings to the terminal
 func Write(items ...string) {
 	vertical := lipgloss.JoinVertical(lipgloss.Top, items...)
-	fmt.Println(ShellMargin(vertical))
+	fmt.Fprintln(output, ShellMargin(vertical))
 }
 
 // WriteNoMargin prints a list of strings to the terminal
 func WriteNoMargin(items ...string) {
 	vertical := lipgloss.JoinVertical(lipgloss.Top, items...)
-	fmt.Println(Shell(vertical))
+	fmt.Fprintln(output, Shell(vertical))
 }
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/huh"
+)
+
+const (
+	// listSeparator separates the options of multiselect values given as
+	// text, such as --set scopes=api,cli
+	listSeparator = ","
+	// defaultSeparator joins the options chosen in multiselect variables
+	defaultSeparator = ", "
+	// defaultTrueValue is rendered when a confirm variable is answered yes
+	defaultTrueValue = "yes"
+	// defaultDateFormat is the layout of date variables
+	defaultDateFormat = "2006-01-02"
+	// today is the value of a date variable standing for the current date
+	today = "today"
+)
+
+// now returns the current time
+var now = time.Now
+
+// parseBool parses a yes or no answer given as text
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "y", "on", "1":
+		return true, nil
+	case "false", "no", "n", "off", "0", "":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected yes or no, got '%s'", value)
+}
+
+// splitList splits the options of a multiselect value given as text
+func splitList(value string) []string {
+	var options []string
+	for _, option := range strings.Split(value, listSeparator) {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// trueValue returns what a confirm variable renders when answered yes
+func (v Variable) trueValue() string {
+	if v.TrueValue != "" {
+		return v.TrueValue
+	}
+	return defaultTrueValue
+}
+
+// separator returns what joins the options chosen in a multiselect variable
+func (v Variable) separator() string {
+	if v.Separator != "" {
+		return v.Separator
+	}
+	return defaultSeparator
+}
+
+// dateFormat returns the layout of a date variable
+func (v Variable) dateFormat() string {
+	if v.Format != "" {
+		return v.Format
+	}
+	return defaultDateFormat
+}
+
+// minSelections returns the least number of options of a multiselect
+// variable, which is one for required variables
+func (v Variable) minSelections() int {
+	if v.MinSelections == 0 && v.Required {
+		return 1
+	}
+	return v.MinSelections
+}
+
+// checkSelections returns an error when n options cannot be chosen in a
+// multiselect variable
+func (v Variable) checkSelections(n int) error {
+	if min := v.minSelections(); n < min {
+		return fmt.Errorf("%s needs at least %d option(s)", v.Name, min)
+	}
+	if v.MaxSelections > 0 && n > v.MaxSelections {
+		return fmt.Errorf("%s takes at most %d option(s)", v.Name, v.MaxSelections)
+	}
+	return nil
+}
+
+// parseDate parses the value of a date variable, given in its format, as
+// YYYY-MM-DD or as "today"
+func (v Variable) parseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == today {
+		return now(), nil
+	}
+
+	for _, layout := range []string{v.dateFormat(), defaultDateFormat} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%s must be a date like %s", v.Name, now().Format(v.dateFormat()))
+}
+
+// normalize turns a value given as text, with --set, --values or by the
+// form, into the value rendered in the message
+func (v Variable) normalize(value string) (string, error) {
+	switch v.Type {
+	case "confirm":
+		if value == v.trueValue() || value == v.FalseValue {
+			return value, nil
+		}
+		yes, err := parseBool(value)
+		if err != nil {
+			return "", fmt.Errorf("%s: %v", v.Name, err)
+		}
+		if yes {
+			return v.trueValue(), nil
+		}
+		return v.FalseValue, nil
+	case "multiselect":
+		selected := splitList(value)
+		for _, option := range selected {
+			if !slices.Contains(v.allOptions(), option) {
+				return "", fmt.Errorf("%s has no option %s", v.Name, option)
+			}
+		}
+		if err := v.checkSelections(len(selected)); err != nil {
+			return "", err
+		}
+		return strings.Join(selected, v.separator()), nil
+	case "number":
+		return strings.TrimSpace(value), nil
+	case "date":
+		if strings.TrimSpace(value) == "" {
+			return "", nil
+		}
+		t, err := v.parseDate(value)
+		if err != nil {
+			return "", err
+		}
+		return t.Format(v.dateFormat()), nil
+	}
+
+	return value, nil
+}
+
+// checkType returns an error when value is not valid for the type of the
+// variable
+func (v Variable) checkType(value string) error {
+	switch v.Type {
+	case "select":
+		if options := v.allOptions(); len(options) > 0 && !slices.Contains(options, value) {
+			return fmt.Errorf("%s must be one of: %s", v.Name, strings.Join(options, ", "))
+		}
+	case "number":
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%s must be a whole number", v.Name)
+		}
+		if v.Min != nil && n < *v.Min {
+			return fmt.Errorf("%s must be at least %d", v.Name, *v.Min)
+		}
+		if v.Max != nil && n > *v.Max {
+			return fmt.Errorf("%s must be at most %d", v.Name, *v.Max)
+		}
+	case "date":
+		if _, err := v.parseDate(value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateType adds the problems of the settings specific to the type of the
+// variable to errs
+func (v Variable) validateType(TemplateId, VarId int, errs *ValidationErrors) {
+	switch v.Type {
+	case "confirm":
+		// Only the default is checked
+	case "multiselect":
+		if len(v.allOptions()) == 0 && !v.dynamicOptions() {
+			errs.add(TemplateId, VarId, "options", v.line, "variable %s has no options", v.Name)
+		}
+		for _, option := range v.allOptions() {
+			if strings.Contains(option, listSeparator) {
+				errs.add(TemplateId, VarId, "options", v.line, "variable %s has option %s containing '%s'", v.Name, option, listSeparator)
+			}
+		}
+		if v.MinSelections < 0 || v.MaxSelections < 0 {
+			errs.add(TemplateId, VarId, "min_selections", v.line, "variable %s has a negative selection limit", v.Name)
+		}
+		if v.MaxSelections > 0 && v.minSelections() > v.MaxSelections {
+			errs.add(TemplateId, VarId, "min_selections", v.line, "variable %s has min_selections greater than max_selections", v.Name)
+		}
+		if v.minSelections() > len(v.allOptions()) && !v.dynamicOptions() {
+			errs.add(TemplateId, VarId, "min_selections", v.line, "variable %s needs more selections than it has options", v.Name)
+		}
+	case "number":
+		if v.Min != nil && v.Max != nil && *v.Min > *v.Max {
+			errs.add(TemplateId, VarId, "min", v.line, "variable %s has min greater than max", v.Name)
+		}
+	case "date":
+		if t := now(); v.Format != "" && t.Format(v.Format) == v.Format {
+			errs.add(TemplateId, VarId, "format", v.line, "variable %s has format %s without any date element", v.Name, v.Format)
+		}
+	default:
+		return
+	}
+
+	// Defaults of dynamic options can only be checked once they are loaded
+	if v.Default == "" || v.dynamicOptions() {
+		return
+	}
+
+	value, err := v.normalize(v.Default)
+	if err == nil {
+		err = v.checkType(value)
+	}
+	if err != nil {
+		errs.add(TemplateId, VarId, "default", v.line, "variable %s has invalid default: %v", v.Name, err)
+	}
+}
+
+// typedField returns the form field of confirm and multiselect variables,
+// which are not answered with text, and a function storing the answer in
+// value as text
+func (v Variable) typedField(value *string) (huh.Field, func()) {
+	switch v.Type {
+	case "confirm":
+		answer, _ := parseBool(*value)
+		answer = answer || *value == v.trueValue()
+		field := huh.NewConfirm().
+			Title(v.title()).
+			Description(v.Description).
+			Value(&answer)
+		return field, func() { *value = strconv.FormatBool(answer) }
+	case "multiselect":
+		selected := splitList(*value)
+		options := make([]huh.Option[string], len(v.allOptions()))
+		for i, option := range v.allOptions() {
+			options[i] = huh.NewOption(option, option)
+		}
+		// The value must be set first for the defaults to be selected
+		field := huh.NewMultiSelect[string]().
+			Title(v.title()).
+			Description(v.Description).
+			Value(&selected).
+			Options(options...).
+			Validate(func(selected []string) error { return v.checkSelections(len(selected)) })
+		if v.MaxSelections > 0 {
+			field.Limit(v.MaxSelections)
+		}
+		return field, func() { *value = strings.Join(selected, listSeparator) }
+	}
+
+	return nil, nil
+}
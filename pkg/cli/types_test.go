@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 3, 9, 12, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { now = time.Now })
+
+	min, max := 1, 9999
+
+	testCases := []struct {
+		description string
+		variable    Variable
+		value       string
+		want        string
+		wantErr     bool
+	}{
+		{description: "confirm yes", variable: Variable{Name: "breaking", Type: "confirm"}, value: "true", want: "yes"},
+		{description: "confirm no", variable: Variable{Name: "breaking", Type: "confirm"}, value: "no", want: ""},
+		{description: "confirm renderings", variable: Variable{Name: "breaking", Type: "confirm", TrueValue: "!", FalseValue: "-"}, value: "y", want: "!"},
+		{description: "confirm rendering given", variable: Variable{Name: "breaking", Type: "confirm", TrueValue: "!"}, value: "!", want: "!"},
+		{description: "confirm invalid", variable: Variable{Name: "breaking", Type: "confirm"}, value: "maybe", wantErr: true},
+		{description: "multiselect", variable: Variable{Name: "scopes", Type: "multiselect", Options: []string{"api", "cli"}}, value: "api, cli", want: "api, cli"},
+		{description: "multiselect separator", variable: Variable{Name: "scopes", Type: "multiselect", Options: []string{"api", "cli"}, Separator: "/"}, value: "api,cli", want: "api/cli"},
+		{description: "multiselect unknown option", variable: Variable{Name: "scopes", Type: "multiselect", Options: []string{"api"}}, value: "web", wantErr: true},
+		{description: "multiselect too many", variable: Variable{Name: "scopes", Type: "multiselect", Options: []string{"api", "cli"}, MaxSelections: 1}, value: "api,cli", wantErr: true},
+		{description: "multiselect required", variable: Variable{Name: "scopes", Type: "multiselect", Options: []string{"api"}, Required: true}, value: "", wantErr: true},
+		{description: "number", variable: Variable{Name: "issue", Type: "number", Min: &min, Max: &max}, value: " 42 ", want: "42"},
+		{description: "date today", variable: Variable{Name: "due", Type: "date"}, value: "today", want: "2024-03-09"},
+		{description: "date format", variable: Variable{Name: "due", Type: "date", Format: "02/01/2006"}, value: "2024-03-09", want: "09/03/2024"},
+		{description: "date invalid", variable: Variable{Name: "due", Type: "date"}, value: "tomorrow", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			got, err := tc.variable.normalize(tc.value)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestCheckType(t *testing.T) {
+	min, max := 1, 9999
+	issue := Variable{Name: "issue", Type: "number", Min: &min, Max: &max}
+
+	for value, valid := range map[string]bool{"42": true, "0": false, "10000": false, "4.2": false, "abc": false} {
+		if err := issue.check(value); (err == nil) != valid {
+			t.Errorf("value %s: expected valid to be %v, got %v", value, valid, err)
+		}
+	}
+
+	due := Variable{Name: "due", Type: "date", Format: "02/01/2006"}
+	if err := due.check("09/03/2024"); err != nil {
+		t.Errorf("expected date to be valid, got %v", err)
+	}
+	if err := due.check("March 9"); err == nil {
+		t.Errorf("expected error for invalid date, got nil")
+	}
+
+	if err := (Variable{Name: "breaking", Type: "confirm", Required: true}).check(""); err != nil {
+		t.Errorf("expected no answer of a confirm variable to be valid, got %v", err)
+	}
+}
+
+func TestValidateTypes(t *testing.T) {
+	min, max := 10, 1
+
+	testCases := []struct {
+		description string
+		variable    Variable
+		want        bool
+	}{
+		{description: "should accept confirm", variable: Variable{Name: "v", Type: "confirm", Default: "yes"}, want: true},
+		{description: "should reject invalid confirm default", variable: Variable{Name: "v", Type: "confirm", Default: "maybe"}},
+		{description: "should accept multiselect", variable: Variable{Name: "v", Type: "multiselect", Options: []string{"a", "b"}, Default: "a", MaxSelections: 2}, want: true},
+		{description: "should reject multiselect without options", variable: Variable{Name: "v", Type: "multiselect"}},
+		{description: "should reject multiselect option with a comma", variable: Variable{Name: "v", Type: "multiselect", Options: []string{"a,b"}}},
+		{description: "should reject min_selections over max_selections", variable: Variable{Name: "v", Type: "multiselect", Options: []string{"a", "b"}, MinSelections: 2, MaxSelections: 1}},
+		{description: "should reject min over max", variable: Variable{Name: "v", Type: "number", Min: &min, Max: &max}},
+		{description: "should reject invalid number default", variable: Variable{Name: "v", Type: "number", Default: "ten"}},
+		{description: "should accept date", variable: Variable{Name: "v", Type: "date", Default: "today", Format: "Jan 2, 2006"}, want: true},
+		{description: "should reject date format without date", variable: Variable{Name: "v", Type: "date", Format: "date"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			err := tc.variable.validate(0, 0)
+			if tc.want && err != nil {
+				t.Errorf("expected no error, got %v", err)
+			}
+			if !tc.want && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestPopulateTemplateTypes(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "%{type}(%{scopes})%{?breaking}!%{/breaking}: %{title}\n\nRefs: #%{issue}\n",
+		Variables: []Variable{
+			{Name: "type"},
+			{Name: "scopes", Type: "multiselect", Options: []string{"api", "cli", "web"}, Separator: ","},
+			{Name: "breaking", Type: "confirm"},
+			{Name: "title"},
+			{Name: "issue", Type: "number"},
+		},
+	}
+
+	values := map[string]string{"type": "feat", "scopes": "api, web", "title": "Add types", "issue": "21"}
+
+	got, err := PopulateTemplate(template, values)
+	if err != nil {
+		t.Fatalf("error populating template: %v", err)
+	}
+
+	want := "feat(api,web): Add types\n\nRefs: #21\n"
+	if got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+
+	match, err := MatchMessage([]Template{template}, "feat(api,cli)!: Add types\n\nRefs: #21\n")
+	if err != nil {
+		t.Fatalf("error matching message: %v", err)
+	}
+	if !match.OK {
+		t.Errorf("expected message to match, got %s", match)
+	}
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ValidationError is a problem found in a template
+type ValidationError struct {
+	// File is the file the template was read from, if any
+	File string `json:"file,omitempty"`
+	// Template is the index of the template in its file, or -1 when the
+	// error is about the whole file
+	Template int `json:"template"`
+	// Variable is the index of the variable, or -1 when the error is about
+	// the template itself
+	Variable int `json:"variable"`
+	// Field is the YAML key the error is about
+	Field string `json:"field,omitempty"`
+	// Line is the YAML line of the error, or zero when unknown
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	var builder strings.Builder
+
+	if e.File != "" {
+		fmt.Fprintf(&builder, "%s: ", e.File)
+	}
+
+	if e.Template >= 0 {
+		fmt.Fprintf(&builder, "Template %d", e.Template)
+	}
+
+	if e.Template >= 0 && e.Variable >= 0 {
+		fmt.Fprintf(&builder, " - Variable %d", e.Variable)
+	}
+
+	if e.Line > 0 {
+		if e.Template >= 0 {
+			builder.WriteString(" ")
+		}
+		fmt.Fprintf(&builder, "(line %d)", e.Line)
+	}
+
+	if e.Template >= 0 || e.Line > 0 {
+		builder.WriteString(": ")
+	}
+
+	builder.WriteString(e.Message)
+
+	return builder.String()
+}
+
+// yamlLine matches the line reported in yaml.v3 errors
+var yamlLine = regexp.MustCompile(`line (\d+)`)
+
+// fileError returns a validation error about a whole file, located at the
+// line reported by err when there is one
+func fileError(file string, err error) ValidationError {
+	e := ValidationError{File: file, Template: -1, Variable: -1, Message: err.Error()}
+
+	if match := yamlLine.FindStringSubmatch(err.Error()); match != nil {
+		e.Line, _ = strconv.Atoi(match[1])
+	}
+
+	return e
+}
+
+// Validate reads the template file at path and returns every problem found in
+// it, including YAML errors. It returns nil when the file is valid.
+func Validate(path string) ValidationErrors {
+	data, err := open(path)
+	if err != nil {
+		return ValidationErrors{fileError(path, err)}
+	}
+
+	_, err = parse(data)
+	if validationErrs, ok := err.(ValidationErrors); ok {
+		return validationErrs.inFile(path)
+	} else if err != nil {
+		return ValidationErrors{fileError(path, err)}
+	}
+
+	return nil
+}
+
+// ValidationErrors is a list of problems found in templates
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// add appends a validation error about a template
+func (e *ValidationErrors) add(template, variable int, field string, line int, format string, args ...any) {
+	*e = append(*e, ValidationError{
+		Template: template,
+		Variable: variable,
+		Field:    field,
+		Line:     line,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// inFile sets the file of every error
+func (e ValidationErrors) inFile(file string) ValidationErrors {
+	for i := range e {
+		e[i].File = file
+	}
+	return e
+}
+
+// orNil returns the errors as an error, or nil when there are none
+func (e ValidationErrors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
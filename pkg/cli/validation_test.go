@@ -0,0 +1,108 @@
+package cli
+
+import (
+	"os"
+	"testing"
+)
+
+var invalidData = `
+- name: Valid
+  text: "%{title}"
+  variables:
+    - name: title
+- name: Invalid
+  text: |
+    [%{type}] %{title|nope}
+  variables:
+    - name: type
+      type: weird
+    - name: title
+`
+
+func TestValidationErrorString(t *testing.T) {
+	testCases := []struct {
+		err  ValidationError
+		want string
+	}{
+		{
+			err:  ValidationError{Template: 1, Variable: -1, Message: "name is empty"},
+			want: "Template 1: name is empty",
+		},
+		{
+			err:  ValidationError{File: "comtemplate.yml", Template: 0, Variable: 2, Line: 12, Message: "variable name is empty"},
+			want: "comtemplate.yml: Template 0 - Variable 2 (line 12): variable name is empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		if got := tc.err.Error(); got != tc.want {
+			t.Errorf("expected '%s', got '%s'", tc.want, got)
+		}
+	}
+}
+
+func TestParseValidationErrors(t *testing.T) {
+	templates, err := parse(invalidData)
+
+	t.Run("should keep valid templates", func(t *testing.T) {
+		if len(templates) != 1 || templates[0].Name != "Valid" {
+			t.Errorf("expected only the valid template, got %v", templates)
+		}
+	})
+
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	t.Run("should report every problem", func(t *testing.T) {
+		if len(errs) != 2 {
+			t.Fatalf("expected two errors, got %d: %v", len(errs), errs)
+		}
+	})
+
+	t.Run("should locate text errors", func(t *testing.T) {
+		want := ValidationError{Template: 1, Variable: -1, Field: "text", Line: 8, Message: "unknown filter 'nope' at column 11"}
+		if errs[0] != want {
+			t.Errorf("expected %#v, got %#v", want, errs[0])
+		}
+	})
+
+	t.Run("should locate variable errors", func(t *testing.T) {
+		want := ValidationError{Template: 1, Variable: 0, Field: "type", Line: 10, Message: "variable type has invalid type weird"}
+		if errs[1] != want {
+			t.Errorf("expected %#v, got %#v", want, errs[1])
+		}
+	})
+}
+
+func TestReadValidationErrors(t *testing.T) {
+	f, err := os.CreateTemp("", "test")
+	if err != nil {
+		t.Fatalf("error creating temp file: %v", err)
+	}
+	defer os.Remove(f.Name())
+
+	_, err = f.Write([]byte(invalidData))
+	if err != nil {
+		t.Fatalf("error writing to temp file: %v", err)
+	}
+	f.Close()
+
+	templates, err := readAll([]string{f.Name()})
+
+	if len(templates) != 1 {
+		t.Errorf("expected one template, got %d", len(templates))
+	}
+
+	errs, ok := err.(ValidationErrors)
+	if !ok {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+
+	for _, e := range errs {
+		if e.File != f.Name() {
+			t.Errorf("expected error file to be '%s', got '%s'", f.Name(), e.File)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ParseSet turns a list of name=value pairs into a map of values
+func ParseSet(pairs []string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	for _, pair := range pairs {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid value '%s': expected name=value", pair)
+		}
+		values[name] = value
+	}
+
+	return values, nil
+}
+
+// scalarValue returns the value of a YAML scalar node exactly as written,
+// and false when n is not a scalar
+func scalarValue(n *yaml.Node) (string, bool) {
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	if n.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	if n.Tag == "!!null" {
+		return "", true
+	}
+	return n.Value, true
+}
+
+// parseValues turns a YAML or JSON mapping of names to scalars into a map of
+// values. Scalars are kept as written, so that 1.10 stays 1.10. Lists of
+// scalars, used for multiselect variables, are joined with commas.
+func parseValues(s string) (map[string]string, error) {
+	raw := make(map[string]yaml.Node)
+
+	err := yaml.Unmarshal([]byte(s), &raw)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing values: %v", err)
+	}
+
+	values := make(map[string]string)
+	for name, node := range raw {
+		if value, ok := scalarValue(&node); ok {
+			values[name] = value
+			continue
+		}
+
+		if node.Kind != yaml.SequenceNode {
+			return nil, fmt.Errorf("error parsing values: value of %s is not a scalar", name)
+		}
+
+		items := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			value, ok := scalarValue(item)
+			if !ok {
+				return nil, fmt.Errorf("error parsing values: value of %s is not a list of scalars", name)
+			}
+			items[i] = value
+		}
+		values[name] = strings.Join(items, listSeparator)
+	}
+
+	return values, nil
+}
+
+// ReadValues reads a YAML or JSON file mapping variable names to values
+func ReadValues(path string) (map[string]string, error) {
+	data, err := open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseValues(data)
+}
+
+// guarded reports whether every use of the variable with the given name, in
+// the text of template and in computed values, is inside a %{?name}...%{/name}
+// block, so that the variable can be left empty without leaving a gap
+func guarded(template Template, name string) bool {
+	unguarded := make(map[string]bool)
+
+	texts := []string{template.Text}
+	for _, variable := range template.Variables {
+		if !variable.computed() {
+			continue
+		}
+		if variable.From != "" {
+			unguarded[variable.From] = true
+		}
+		texts = append(texts, variable.Value)
+	}
+
+	for _, text := range texts {
+		nodes, err := parseText(text)
+		if err != nil {
+			return false
+		}
+		unguardedNames(nodes, nil, unguarded)
+	}
+
+	return !unguarded[name]
+}
+
+// FillOptional returns a copy of values where optional variables without a
+// value are left empty when all their uses are inside a block of their own,
+// which is then dropped. Other variables without a value are still missing.
+func FillOptional(template Template, values map[string]string) map[string]string {
+	filled := make(map[string]string)
+	for name, value := range values {
+		filled[name] = value
+	}
+
+	for _, variable := range MissingVariables(template, filled) {
+		if !variable.Required && guarded(template, variable.Name) {
+			filled[variable.Name] = ""
+		}
+	}
+
+	return filled
+}
+
+// MissingVariables returns the variables of template without a value
+func MissingVariables(template Template, values map[string]string) []Variable {
+	var missing []Variable
+
+	for _, variable := range template.Variables {
+		if _, ok := values[variable.Name]; !ok && !variable.computed() {
+			missing = append(missing, variable)
+		}
+	}
+
+	return missing
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSet(t *testing.T) {
+	t.Run("should split on the first equal sign", func(t *testing.T) {
+		values, err := ParseSet([]string{"type=feat", "description=a=b", "body="})
+		if err != nil {
+			t.Fatalf("error parsing values: %v", err)
+		}
+
+		want := map[string]string{"type": "feat", "description": "a=b", "body": ""}
+		for name, value := range want {
+			if values[name] != value {
+				t.Errorf("expected %s to be '%s', got '%s'", name, value, values[name])
+			}
+		}
+	})
+
+	t.Run("should fail without equal sign", func(t *testing.T) {
+		if _, err := ParseSet([]string{"type"}); err == nil {
+			t.Errorf("expected error, got nil")
+		}
+	})
+}
+
+func TestParseValues(t *testing.T) {
+	testCases := []struct {
+		description string
+		data        string
+		want        map[string]string
+		wantErr     bool
+	}{
+		{
+			description: "should read yaml",
+			data:        "type: feat\nissue: 42\nbody:\n",
+			want:        map[string]string{"type": "feat", "issue": "42", "body": ""},
+		},
+		{
+			description: "should read json",
+			data:        `{"type": "fix", "breaking": true}`,
+			want:        map[string]string{"type": "fix", "breaking": "true"},
+		},
+		{
+			description: "should join lists",
+			data:        "scopes: [api, cli]\n",
+			want:        map[string]string{"scopes": "api,cli"},
+		},
+		{
+			description: "should keep numbers as written",
+			data:        "version: 1.10\nticket: 0123\nsize: 1e3\nid: 12345678901234567890\n",
+			want:        map[string]string{"version": "1.10", "ticket": "0123", "size": "1e3", "id": "12345678901234567890"},
+		},
+		{
+			description: "should keep list items as written",
+			data:        "versions: [1.10, 0123]\nnone: null\n",
+			want:        map[string]string{"versions": "1.10,0123", "none": ""},
+		},
+		{
+			description: "should fail for nested values",
+			data:        "type:\n  name: feat\n",
+			wantErr:     true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			values, err := parseValues(tc.data)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got nil")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("error parsing values: %v", err)
+			}
+
+			for name, value := range tc.want {
+				if values[name] != value {
+					t.Errorf("expected %s to be '%s', got '%s'", name, value, values[name])
+				}
+			}
+		})
+	}
+}
+
+func TestMissingVariables(t *testing.T) {
+	templates, err := parse(mockData)
+	if err != nil {
+		t.Fatalf("error parsing yaml: %v", err)
+	}
+
+	missing := MissingVariables(templates[1], map[string]string{"title": "Test title"})
+
+	if len(missing) != 2 || missing[0].Name != "identifier" || missing[1].Name != "body" {
+		t.Errorf("expected identifier and body to be missing, got %v", missing)
+	}
+}
+
+func TestFillOptional(t *testing.T) {
+	template := Template{
+		Name: "Test",
+		Text: "%{type}: %{title}\n\n%{?body}%{body}%{/body}\n%{?issue}Refs: %{issue}%{/issue} %{?scope}%{scope}%{/scope}%{refs}",
+		Variables: []Variable{
+			{Name: "type"},
+			{Name: "title", Required: true},
+			{Name: "body", Type: "text"},
+			{Name: "issue", Required: true},
+			{Name: "scope"},
+			{Name: "ticket"},
+			{Name: "refs", Type: "computed", Value: "%{ticket}"},
+		},
+	}
+
+	values := FillOptional(template, map[string]string{"scope": "api"})
+
+	var names []string
+	for _, variable := range MissingVariables(template, values) {
+		names = append(names, variable.Name)
+	}
+
+	// body is optional and only used in its block, and scope has a value
+	if got := strings.Join(names, ","); got != "type,title,issue,ticket" {
+		t.Errorf("expected type, title, issue and ticket to be missing, got %s", got)
+	}
+	if values["body"] != "" || values["scope"] != "api" {
+		t.Errorf("expected body to be empty and scope kept, got %v", values)
+	}
+}
+
+func TestPopulateFromFormWithValues(t *testing.T) {
+	templates, err := parse(mockData)
+	if err != nil {
+		t.Fatalf("error parsing yaml: %v", err)
+	}
+
+	// No form is shown when every variable has a value
+	got, err := PopulateFromForm(templates[0], map[string]string{"title": "Test title", "body": "Test body"})
+	if err != nil {
+		t.Fatalf("error populating template: %v", err)
+	}
+
+	want := "Test title\n\nTest body\n"
+	if got != want {
+		t.Errorf("expected string to be '%s', got '%s'", want, got)
+	}
+}